@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/config"
+	"github.com/pablopin/docker-image-checker/internal/docker"
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/metrics"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/notification"
+	"github.com/pablopin/docker-image-checker/internal/report"
+	"github.com/pablopin/docker-image-checker/internal/state"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// App encapsula la lógica de la aplicación
+type App struct {
+	checker  *docker.Checker
+	notifier *notification.NotificationManager
+	// throttle es nil si no hay horas de silencio ni límites de envío
+	throttle *notification.Throttle
+	config   *config.Config
+	// state es nil si no se guarda lo notificado entre ejecuciones
+	state *state.Store
+	// memoryState guarda los silencios cuando no hay fichero de estado
+	memoryState *state.State
+
+	// progress destino de los mensajes de progreso
+	progress io.Writer
+	// output destino del reporte de cada verificación
+	output reportOutput
+	// publisher es nil si no se publican reportes en un directorio
+	publisher *report.Publisher
+
+	// runMu evita verificaciones simultáneas (cron, bot, API)
+	runMu sync.Mutex
+	// stateMu serializa las lecturas y escrituras del estado de
+	// notificaciones. Es independiente de runMu para que /ignore no espere a
+	// que termine una verificación en curso.
+	stateMu sync.Mutex
+	// mu protege lastReport, que se consulta mientras corre una verificación
+	mu         sync.Mutex
+	lastReport *model.CheckReport
+}
+
+// reportOutput formato y destino del reporte
+type reportOutput struct {
+	format report.Format
+	// file vacío escribe el reporte en stdout
+	file    string
+	options report.WriteOptions
+}
+
+// NewApp crea la aplicación; los mensajes de progreso se escriben en progress
+func NewApp(checker *docker.Checker, notifier *notification.NotificationManager, throttle *notification.Throttle, cfg *config.Config, progress io.Writer) *App {
+	app := &App{
+		checker:     checker,
+		notifier:    notifier,
+		throttle:    throttle,
+		config:      cfg,
+		memoryState: state.New(),
+		progress:    progress,
+		output:      reportOutput{format: report.FormatText},
+	}
+	if cfg.Notifications.StateFile != "" {
+		app.state = state.NewStore(cfg.Notifications.StateFile)
+	}
+	return app
+}
+
+// runOnce ejecuta la verificación una sola vez
+func (a *App) runOnce() error {
+	_, err := a.RunCheck(context.Background())
+	return err
+}
+
+// RunCheck ejecuta una verificación completa, notifica y devuelve el reporte
+func (a *App) RunCheck(ctx context.Context) (_ *model.CheckReport, err error) {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+
+	ctx, span := tracing.Start(ctx, "App.RunCheck")
+	defer func() { tracing.End(span, err) }()
+
+	fmt.Fprintf(a.progress, "%s%s%s\n", ColorBlue, i18n.T("check.start"), ColorReset)
+
+	start := time.Now()
+	report, err := a.checker.CheckAll(ctx)
+	metrics.CheckDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.Runs.WithLabelValues(metrics.ResultFailure).Inc()
+		return nil, fmt.Errorf("failed to check containers: %w", err)
+	}
+
+	// Añadir información del hostname
+	hostname, _ := os.Hostname()
+	report.Hostname = hostname
+	report.Timestamp = time.Now()
+
+	metrics.Runs.WithLabelValues(metrics.ResultSuccess).Inc()
+	metrics.RecordReport(report)
+	span.SetAttributes(
+		attribute.Int("containers.total", report.Total),
+		attribute.Int("containers.available", len(report.Available)),
+		attribute.Int("containers.failed", len(report.Failed)),
+	)
+
+	// Mostrar resultados en consola o en el formato pedido
+	a.writeReport(report)
+
+	a.mu.Lock()
+	a.lastReport = report
+	a.mu.Unlock()
+
+	// Calcular qué ha cambiado desde la última notificación. Los
+	// contenedores silenciados (/ignore) no se notifican.
+	notifyReport, changes, err := a.diffState(report)
+	if err != nil {
+		return report, fmt.Errorf("failed to load notification state: %w", err)
+	}
+
+	if !changes.IsEmpty() {
+		fmt.Fprintln(a.progress, i18n.T("notify.sending", len(notifyReport.Available), len(notifyReport.Failed)))
+
+		notificationData := &model.NotificationData{
+			Report:   notifyReport,
+			Hostname: hostname,
+			Changes:  changes,
+		}
+
+		// Basta con que un notificador reciba la notificación para darla por
+		// enviada: si no, los que sí la recibieron repetirían los mismos
+		// cambios en la siguiente ejecución. Los reintentos de los que fallan
+		// son cosa del outbox.
+		err := a.notify(ctx, notificationData)
+		if err != nil {
+			fmt.Fprintf(a.progress, "%sWarning: Failed to send notifications: %v%s\n", ColorYellow, err, ColorReset)
+		} else {
+			fmt.Fprintln(a.progress, i18n.T("notify.sent"))
+		}
+		if notification.Delivered(err) {
+			err := a.updateState(func(st *state.State) {
+				st.Apply(notifyReport, changes, report.Timestamp)
+			})
+			if err != nil {
+				fmt.Fprintf(a.progress, "%sWarning: Failed to save notification state: %v%s\n", ColorYellow, err, ColorReset)
+			}
+		}
+	} else if a.state != nil {
+		fmt.Fprintln(a.progress, i18n.T("notify.no_changes"))
+	} else {
+		fmt.Fprintln(a.progress, i18n.T("notify.nothing"))
+	}
+
+	fmt.Fprintf(a.progress, "%s%s%s\n", ColorBlue, i18n.T("check.done"), ColorReset)
+	return report, nil
+}
+
+// writeReport muestra el reporte en consola y, si se ha pedido, lo escribe
+// en otro formato en stdout o en un fichero y lo publica en el directorio
+// de reportes
+func (a *App) writeReport(checkReport *model.CheckReport) {
+	var err error
+	switch {
+	case a.output.file != "":
+		err = errors.Join(
+			report.WriteText(os.Stdout, checkReport, true),
+			report.WriteFile(a.output.file, a.output.format, checkReport, a.output.options),
+		)
+	case a.output.format == report.FormatText:
+		err = report.WriteText(os.Stdout, checkReport, true)
+	default:
+		err = report.Write(os.Stdout, a.output.format, checkReport, a.output.options)
+	}
+	if err != nil {
+		fmt.Fprintf(a.progress, "%sWarning: Failed to write %s report: %v%s\n", ColorYellow, a.output.format, err, ColorReset)
+	}
+
+	if a.publisher != nil {
+		if err := a.publisher.Publish(checkReport); err != nil {
+			fmt.Fprintf(a.progress, "%sWarning: %v%s\n", ColorYellow, err, ColorReset)
+		}
+	}
+}
+
+// notify envía la notificación a través del control de envío, si lo hay
+func (a *App) notify(ctx context.Context, data *model.NotificationData) error {
+	if a.throttle != nil {
+		return a.throttle.NotifyAll(ctx, data)
+	}
+	return a.notifier.NotifyAll(ctx, data)
+}
+
+// LastReport devuelve el último reporte generado
+func (a *App) LastReport() *model.CheckReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastReport
+}
+
+// Ignore silencia las notificaciones de un contenedor durante un tiempo. No
+// espera a que termine una verificación en curso.
+func (a *App) Ignore(container string, duration time.Duration) error {
+	return a.updateState(func(st *state.State) {
+		st.Snooze(container, time.Now().Add(duration))
+	})
+}
+
+// diffState devuelve el reporte sin los contenedores silenciados y lo que ha
+// cambiado desde la última notificación. Sin fichero de estado se recuerda
+// todo lo pendiente en cada ejecución y el estado en memoria solo detecta lo
+// resuelto desde la ejecución anterior.
+func (a *App) diffState(report *model.CheckReport) (*model.CheckReport, *model.ChangeSet, error) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	st, err := a.loadState()
+	if err != nil {
+		return nil, nil, err
+	}
+	notifyReport := st.WithoutSnoozed(report, report.Timestamp)
+
+	reminder := time.Duration(a.config.Notifications.ReminderDays) * 24 * time.Hour
+	if a.state == nil {
+		reminder = state.RemindEveryRun
+	}
+	return notifyReport, st.Diff(notifyReport, report.Timestamp, reminder), nil
+}
+
+// updateState modifica el estado guardado. Se vuelve a cargar en cada
+// cambio para no pisar los silencios añadidos mientras se notificaba.
+func (a *App) updateState(update func(st *state.State)) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	st, err := a.loadState()
+	if err != nil {
+		return err
+	}
+	update(st)
+	return a.saveState(st)
+}
+
+// loadState carga el estado desde disco o, si no hay fichero, el estado en
+// memoria; se llama con stateMu tomado
+func (a *App) loadState() (*state.State, error) {
+	if a.state == nil {
+		return a.memoryState, nil
+	}
+	return a.state.Load()
+}
+
+// saveState guarda el estado si hay fichero configurado
+func (a *App) saveState(st *state.State) error {
+	if a.state == nil {
+		return nil
+	}
+	return a.state.Save(st)
+}
+
+// runDaemon ejecuta la verificación de forma continua
+func (a *App) runDaemon() {
+	c := cron.New()
+
+	_, err := c.AddFunc(a.config.Checker.Schedule, func() {
+		if err := a.runOnce(); err != nil {
+			log.Printf("%sError running check: %v%s", ColorRed, err, ColorReset)
+		}
+	})
+	if err != nil {
+		log.Fatalf("%sInvalid cron schedule configuration: %v%s", ColorRed, err, ColorReset)
+	}
+
+	fmt.Fprintf(a.progress, "%s%s%s\n", ColorBlue, i18n.T("daemon.start", a.config.Checker.Schedule), ColorReset)
+
+	// Canal para manejar señales de interrupción
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	c.Start()
+	defer c.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Reintentos de las notificaciones pendientes del outbox
+	go a.notifier.RunOutbox(ctx)
+
+	// Entrega de lo retenido por horas de silencio o límites de envío
+	if a.throttle != nil {
+		go a.throttle.Run(ctx)
+	}
+
+	// Métricas de Prometheus y API REST
+	if a.config.HTTP.Enabled() {
+		if err := a.startHTTPServer(ctx); err != nil {
+			log.Fatalf("%sError starting HTTP server: %v%s", ColorRed, err, ColorReset)
+		}
+	}
+
+	// Bot de comandos de Telegram
+	if botCfg := a.config.Notifications.Telegram.Bot; botCfg.Enabled {
+		bot, err := notification.NewTelegramBot(notification.TelegramBotOptions{
+			BotToken:       a.config.TelegramBotToken,
+			AllowedChatIDs: botCfg.AllowedChatIDs,
+			AllowedUserIDs: botCfg.AllowedUserIDs,
+			PollTimeout:    botCfg.PollTimeout,
+		}, a)
+		if err != nil {
+			log.Fatalf("%sError creating Telegram bot: %v%s", ColorRed, err, ColorReset)
+		}
+		go func() {
+			if err := bot.Run(ctx); err != nil {
+				log.Printf("%sTelegram bot stopped: %v%s", ColorRed, err, ColorReset)
+			}
+		}()
+		fmt.Fprintln(a.progress, i18n.T("daemon.bot"))
+	}
+
+	// Ejecutar primera verificación inmediatamente
+	if err := a.runOnce(); err != nil {
+		log.Printf("%sError in initial check: %v%s", ColorRed, err, ColorReset)
+	}
+
+	// Esperar señal de interrupción
+	<-sigChan
+	fmt.Fprintf(a.progress, "%s%s%s\n", ColorBlue, i18n.T("daemon.stop"), ColorReset)
+}
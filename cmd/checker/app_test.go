@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/config"
+	"github.com/pablopin/docker-image-checker/internal/docker"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/notification"
+	"github.com/pablopin/docker-image-checker/internal/state"
+)
+
+// blockingObserver notificador que avisa en started y espera a release
+type blockingObserver struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingObserver) Notify(ctx context.Context, data *model.NotificationData) error {
+	close(b.started)
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newTestApp crea una App contra el daemon de Docker falso con un único
+// notificador
+func newTestApp(t *testing.T, cfg *config.Config, observer notification.Observer) *App {
+	t.Helper()
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(fakeDockerAPI(t).URL, "http://"))
+	base := http.DefaultTransport
+	http.DefaultTransport = dockerHubTransport{base: base}
+	t.Cleanup(func() { http.DefaultTransport = base })
+
+	client, err := docker.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	manager := notification.NewNotificationManager()
+	manager.SetProgress(io.Discard)
+	manager.Subscribe(notification.WithName("ops", observer))
+	return NewApp(docker.NewChecker(client), manager, nil, cfg, io.Discard)
+}
+
+func TestIgnoreDuringCheck(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Notifications.StateFile = filepath.Join(t.TempDir(), "state.json")
+	observer := &blockingObserver{started: make(chan struct{}), release: make(chan struct{})}
+	app := newTestApp(t, cfg, observer)
+
+	done := make(chan error)
+	go func() {
+		_, err := app.RunCheck(context.Background())
+		done <- err
+	}()
+	<-observer.started
+
+	// La verificación está notificando: /ignore no debe esperar a que acabe
+	ignored := make(chan error)
+	go func() { ignored <- app.Ignore("db", time.Hour) }()
+	select {
+	case err := <-ignored:
+		if err != nil {
+			t.Fatalf("Ignore: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Ignore blocked until the running check finished")
+	}
+
+	close(observer.release)
+	if err := <-done; err != nil {
+		t.Fatalf("RunCheck: %v", err)
+	}
+
+	// Guardar lo notificado no pisa el silencio añadido durante el envío
+	st, err := state.NewStore(cfg.Notifications.StateFile).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.IsSnoozed("db", time.Now()) {
+		t.Error("the snooze added during the check was lost")
+	}
+	if entry := st.Pending["web"]; entry == nil || entry.LatestVersion != "1.25.4" {
+		t.Errorf("notified update not saved: %+v", st.Pending)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/notification"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// commandUsage ayuda de los subcomandos
+const commandUsage = `usage:
+  checker template render [-template file | -notifier name [-payload]] [-fixture report.json | -event updates|failures|recovery]
+  checker notify test [-notifier name] [-event updates|failures|recovery]`
+
+// runCommand ejecuta un subcomando ("template render" o "notify test")
+func runCommand(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("missing subcommand\n%s", commandUsage)
+	}
+
+	switch args[0] + " " + args[1] {
+	case "template render":
+		return templateRender(args[2:])
+	case "notify test":
+		return notifyTest(args[2:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", strings.Join(args[:2], " "), commandUsage)
+	}
+}
+
+// templateRender muestra el mensaje (o el payload de la API) que generaría
+// una plantilla con un reporte de ejemplo o leído de un fichero JSON
+func templateRender(args []string) error {
+	fs := flag.NewFlagSet("template render", flag.ExitOnError)
+	var (
+		configPath   = fs.String("config", "configs/config.yaml", "Path to configuration file (with -notifier)")
+		templatePath = fs.String("template", "", "Template file to render (default: built-in template)")
+		notifierName = fs.String("notifier", "", "Render with a configured notifier, including its escaping")
+		fixturePath  = fs.String("fixture", "", "JSON file with a CheckReport or NotificationData (default: built-in sample)")
+		event        = fs.String("event", string(model.EventUpdates), "Built-in sample to use: updates, failures or recovery")
+		payload      = fs.Bool("payload", false, "Print the exact API request bodies instead of the message (requires -notifier)")
+	)
+	fs.Parse(args)
+
+	if *templatePath != "" && *notifierName != "" {
+		return fmt.Errorf("use either -template or -notifier")
+	}
+	if *payload && *notifierName == "" {
+		return fmt.Errorf("-payload requires -notifier")
+	}
+
+	data, err := commandData(*fixturePath, *event)
+	if err != nil {
+		return err
+	}
+
+	if *notifierName == "" {
+		set, err := templates.Load(templates.Options{Name: "template", Path: *templatePath})
+		if err != nil {
+			return err
+		}
+		message, err := set.Render(data)
+		if err != nil {
+			return err
+		}
+		fmt.Println(message)
+		return nil
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	manager, err := setupNotifications(cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+	observer := findObserver(manager, *notifierName)
+	if observer == nil {
+		return fmt.Errorf("unknown notifier %q", *notifierName)
+	}
+
+	preview, err := notification.PreviewOf(observer, data)
+	if err != nil {
+		return err
+	}
+	if !*payload {
+		fmt.Println(preview.Message)
+		return nil
+	}
+	for i, body := range preview.Payloads {
+		if len(preview.Payloads) > 1 {
+			fmt.Printf("--- %d/%d ---\n", i+1, len(preview.Payloads))
+		}
+		fmt.Println(string(body))
+	}
+	return nil
+}
+
+// notifyTest envía una notificación de ejemplo por cada notificador configurado
+func notifyTest(args []string) error {
+	fs := flag.NewFlagSet("notify test", flag.ExitOnError)
+	var (
+		configPath   = fs.String("config", "configs/config.yaml", "Path to configuration file")
+		notifierName = fs.String("notifier", "", "Only test this notifier")
+		event        = fs.String("event", string(model.EventUpdates), "Sample to send: updates, failures or recovery")
+	)
+	fs.Parse(args)
+
+	data, err := commandData("", *event)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	manager, err := setupNotifications(cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	var tested, failed int
+	for _, observer := range manager.Observers() {
+		name := notification.ObserverName(observer)
+		if *notifierName != "" && name != *notifierName {
+			continue
+		}
+		tested++
+
+		if err := manager.NotifyOne(context.Background(), observer, data); err != nil {
+			fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+			failed++
+			continue
+		}
+		fmt.Printf("%s✅ %s%s\n", ColorGreen, name, ColorReset)
+	}
+
+	switch {
+	case tested == 0 && *notifierName != "":
+		return fmt.Errorf("unknown notifier %q", *notifierName)
+	case tested == 0:
+		return fmt.Errorf("no notifiers configured")
+	case failed > 0:
+		return fmt.Errorf("%d of %d notifiers failed", failed, tested)
+	}
+	return nil
+}
+
+// commandData devuelve los datos de un fichero JSON o el ejemplo del evento indicado
+func commandData(fixturePath, event string) (*model.NotificationData, error) {
+	if fixturePath != "" {
+		return templates.LoadFixture(fixturePath)
+	}
+
+	for _, known := range model.EventTypes {
+		if event == string(known) {
+			hostname, err := os.Hostname()
+			if err != nil {
+				hostname = "docker-host"
+			}
+			return templates.SampleData(known, hostname), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown event %q (expected updates, failures or recovery)", event)
+}
+
+// findObserver busca un notificador configurado por su nombre
+func findObserver(manager *notification.NotificationManager, name string) notification.Observer {
+	for _, observer := range manager.Observers() {
+		if notification.ObserverName(observer) == name {
+			return observer
+		}
+	}
+	return nil
+}
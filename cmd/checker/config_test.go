@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+)
+
+func TestLoadConfigValidation(t *testing.T) {
+	previous := i18n.Language()
+	t.Cleanup(func() { i18n.SetLanguage(previous) })
+
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{name: "valid", yaml: "language: es\nreports:\n  formats: [html, json]\n"},
+		{name: "unsupported language", yaml: "language: fr\n", want: `unsupported language "fr"`},
+		{name: "unknown report format", yaml: "reports:\n  formats: [html, pdf]\n", want: `reports.formats: unknown output format "pdf"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			content := "checker:\n  schedule: \"0 * * * *\"\n" + tt.yaml
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := loadConfig(path)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("loadConfig: %v", err)
+				}
+				if got := i18n.Language(); got != cfg.Language {
+					t.Errorf("language = %q, want %q", got, cfg.Language)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadConfig error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
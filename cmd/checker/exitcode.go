@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// Códigos de salida de una verificación única (--once). El 1 queda para
+// errores que impiden verificar y el 2 para errores de uso de los flags.
+const (
+	ExitOK      = 0
+	ExitUsage   = 2
+	ExitUpdates = 3
+	ExitFailed  = 4
+)
+
+// failOnRule hallazgo que provoca un código de salida distinto de cero
+type failOnRule string
+
+const (
+	failOnAny     failOnRule = "any"
+	failOnUpdates failOnRule = "updates"
+	failOnMajor   failOnRule = "major"
+	failOnFailed  failOnRule = "failed"
+	failOnNone    failOnRule = "none"
+)
+
+// parseFailOn interpreta --fail-on: una lista separada por comas de any,
+// updates, major, failed o none
+func parseFailOn(value string) ([]failOnRule, error) {
+	var rules []failOnRule
+	for _, item := range strings.Split(value, ",") {
+		rule := failOnRule(strings.TrimSpace(strings.ToLower(item)))
+		switch rule {
+		case failOnAny, failOnUpdates, failOnMajor, failOnFailed:
+			rules = append(rules, rule)
+		case failOnNone:
+			if len(strings.Split(value, ",")) > 1 {
+				return nil, fmt.Errorf("--fail-on none cannot be combined with other values")
+			}
+		default:
+			return nil, fmt.Errorf("unknown --fail-on value %q (expected any, updates, major, failed or none)", item)
+		}
+	}
+	return rules, nil
+}
+
+// exitCode calcula el código de salida del reporte según --fail-on. Las
+// verificaciones fallidas tienen prioridad sobre las actualizaciones.
+func exitCode(report *model.CheckReport, rules []failOnRule) int {
+	var onFailures, onUpdates, onMajor bool
+	for _, rule := range rules {
+		switch rule {
+		case failOnAny:
+			onFailures, onUpdates = true, true
+		case failOnUpdates:
+			onUpdates = true
+		case failOnMajor:
+			onMajor = true
+		case failOnFailed:
+			onFailures = true
+		}
+	}
+
+	switch {
+	case onFailures && len(report.Failed) > 0:
+		return ExitFailed
+	case onUpdates && len(report.Available) > 0:
+		return ExitUpdates
+	case onMajor && report.HasMajorUpdates():
+		return ExitUpdates
+	default:
+		return ExitOK
+	}
+}
+
+// exitUsage termina con el código de uso, igual que los errores del paquete flag
+func exitUsage(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	flag.Usage()
+	os.Exit(ExitUsage)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func TestExitCode(t *testing.T) {
+	var (
+		patch = model.UpdateInfo{
+			Container:      model.Container{Name: "web", ImageName: "nginx:1.25.3"},
+			CurrentVersion: "1.25.3",
+			LatestVersion:  "1.25.4",
+		}
+		major = model.UpdateInfo{
+			Container:      model.Container{Name: "db", ImageName: "postgres:15.5"},
+			CurrentVersion: "15.5",
+			LatestVersion:  "16.1",
+		}
+		failed = model.UpdateInfo{
+			Container: model.Container{Name: "cache", ImageName: "redis:7.2"},
+			Error:     errors.New("registry unavailable"),
+		}
+	)
+	reports := map[string]*model.CheckReport{
+		"clean":        {},
+		"patch":        {Available: []model.UpdateInfo{patch}},
+		"major":        {Available: []model.UpdateInfo{patch, major}},
+		"failed":       {Failed: []model.UpdateInfo{failed}},
+		"patch+failed": {Available: []model.UpdateInfo{patch}, Failed: []model.UpdateInfo{failed}},
+		"major+failed": {Available: []model.UpdateInfo{major}, Failed: []model.UpdateInfo{failed}},
+	}
+
+	// Código esperado por valor de --fail-on y reporte
+	tests := []struct {
+		failOn string
+		want   map[string]int
+	}{
+		{"any", map[string]int{"clean": ExitOK, "patch": ExitUpdates, "major": ExitUpdates, "failed": ExitFailed, "patch+failed": ExitFailed, "major+failed": ExitFailed}},
+		{"updates", map[string]int{"clean": ExitOK, "patch": ExitUpdates, "major": ExitUpdates, "failed": ExitOK, "patch+failed": ExitUpdates, "major+failed": ExitUpdates}},
+		{"major", map[string]int{"clean": ExitOK, "patch": ExitOK, "major": ExitUpdates, "failed": ExitOK, "patch+failed": ExitOK, "major+failed": ExitUpdates}},
+		{"failed", map[string]int{"clean": ExitOK, "patch": ExitOK, "major": ExitOK, "failed": ExitFailed, "patch+failed": ExitFailed, "major+failed": ExitFailed}},
+		{"none", map[string]int{"clean": ExitOK, "patch": ExitOK, "major": ExitOK, "failed": ExitOK, "patch+failed": ExitOK, "major+failed": ExitOK}},
+		{"major,failed", map[string]int{"clean": ExitOK, "patch": ExitOK, "major": ExitUpdates, "failed": ExitFailed, "patch+failed": ExitFailed, "major+failed": ExitFailed}},
+		{" Updates , MAJOR ", map[string]int{"clean": ExitOK, "patch": ExitUpdates, "major": ExitUpdates, "failed": ExitOK, "patch+failed": ExitUpdates, "major+failed": ExitUpdates}},
+	}
+	for _, tt := range tests {
+		rules, err := parseFailOn(tt.failOn)
+		if err != nil {
+			t.Fatalf("parseFailOn(%q): %v", tt.failOn, err)
+		}
+		for name, report := range reports {
+			if got := exitCode(report, rules); got != tt.want[name] {
+				t.Errorf("--fail-on %q with %s report: exit code %d, want %d", tt.failOn, name, got, tt.want[name])
+			}
+		}
+	}
+}
+
+// Los valores no válidos terminan con ExitUsage a través de exitUsage
+func TestParseFailOnErrors(t *testing.T) {
+	for _, value := range []string{"", "all", "updates,", "none,failed", "failed,none"} {
+		if rules, err := parseFailOn(value); err == nil {
+			t.Errorf("parseFailOn(%q) = %v, want error", value, rules)
+		}
+	}
+}
+
+// TestExitUsage ejecuta exitUsage en un proceso hijo para comprobar el código
+func TestExitUsage(t *testing.T) {
+	if os.Getenv("TEST_EXIT_USAGE") == "1" {
+		_, err := parseFailOn("all")
+		exitUsage(err)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExitUsage$")
+	cmd.Env = append(os.Environ(), "TEST_EXIT_USAGE=1")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != ExitUsage {
+		t.Fatalf("exitUsage exited with %v, want code %d", err, ExitUsage)
+	}
+}
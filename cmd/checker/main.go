@@ -4,19 +4,24 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
+	// Zonas horarias embebidas para dateIn en imágenes sin tzdata
+	_ "time/tzdata"
 
 	"github.com/pablopin/docker-image-checker/internal/config"
 	"github.com/pablopin/docker-image-checker/internal/docker"
-	"github.com/pablopin/docker-image-checker/internal/model"
-	"github.com/pablopin/docker-image-checker/internal/notification"
-	"github.com/robfig/cron/v3"
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/report"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
 )
 
+// tracingShutdownTimeout espera para exportar las trazas pendientes al salir
+const tracingShutdownTimeout = 5 * time.Second
+
 const (
 	ColorGreen  = "\033[92m"
 	ColorYellow = "\033[93m"
@@ -26,19 +31,52 @@ const (
 )
 
 func main() {
+	// Subcomandos: template render, notify test
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalf("%s%v%s", ColorRed, err, ColorReset)
+		}
+		return
+	}
+
 	var (
 		configPath = flag.String("config", "configs/config.yaml", "Path to configuration file")
 		daemon     = flag.Bool("daemon", false, "Run as daemon")
 		once       = flag.Bool("once", false, "Run check once and exit")
+		output     = flag.String("output", "text", "Report format: text, json, yaml, csv, junit, sarif, html or markdown")
+		outputFile = flag.String("output-file", "", "Write the report to this file instead of stdout")
+		failOn     = flag.String("fail-on", "any", "Findings that make a single run exit non-zero: any, updates, major, failed or none (comma-separated)")
 	)
 	flag.Parse()
 
+	failOnRules, err := parseFailOn(*failOn)
+	if err != nil {
+		exitUsage(err)
+	}
+
+	outputFormat, err := report.ParseFormat(*output)
+	if err != nil {
+		exitUsage(err)
+	}
+	// Con un formato para máquinas en stdout, los mensajes de progreso de la
+	// aplicación y los notificadores se escriben en stderr
+	var progress io.Writer = os.Stdout
+	if outputFormat != report.FormatText && *outputFile == "" {
+		progress = os.Stderr
+	}
+
 	// Cargar configuración
-	cfg, err := config.Load(*configPath)
+	cfg, err := loadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("%sError loading configuration: %v%s", ColorRed, err, ColorReset)
 	}
 
+	shutdownTracing, err := setupTracing(cfg)
+	if err != nil {
+		log.Fatalf("%s%v%s", ColorRed, err, ColorReset)
+	}
+	defer shutdownTracing()
+
 	// Crear cliente Docker
 	dockerClient, err := docker.NewDockerClient()
 	if err != nil {
@@ -50,156 +88,109 @@ func main() {
 	checker := docker.NewChecker(dockerClient)
 
 	// Configurar sistema de notificaciones
-	notificationManager := notification.NewNotificationManager()
-
-	if cfg.Notifications.Telegram.Enabled {
-		telegramNotifier, err := notification.NewTelegramNotifier(
-			cfg.TelegramBotToken,
-			cfg.TelegramChatID,
-			cfg.Notifications.Telegram.TemplateFile,
-		)
-		if err != nil {
-			log.Fatalf("%sError creating Telegram notifier: %v%s", ColorRed, err, ColorReset)
-		}
-		notificationManager.Subscribe(telegramNotifier)
+	notificationManager, err := setupNotifications(cfg, progress)
+	if err != nil {
+		log.Fatalf("%s%v%s", ColorRed, err, ColorReset)
 	}
 
-	// Crear aplicación
-	app := &App{
-		checker:  checker,
-		notifier: notificationManager,
-		config:   cfg,
+	notificationThrottle, err := setupThrottle(cfg, notificationManager, progress)
+	if err != nil {
+		log.Fatalf("%s%v%s", ColorRed, err, ColorReset)
 	}
 
-	if *once {
-		// Ejecutar una sola vez
-		if err := app.runOnce(); err != nil {
-			log.Fatalf("%sError running check: %v%s", ColorRed, err, ColorReset)
-		}
-		return
+	// Crear aplicación
+	app := NewApp(checker, notificationManager, notificationThrottle, cfg, progress)
+	app.output = reportOutput{
+		format:  outputFormat,
+		file:    *outputFile,
+		options: report.WriteOptions{SARIFBaseDir: cfg.Reports.SARIFBaseDir},
+	}
+	app.publisher, err = setupPublisher(cfg)
+	if err != nil {
+		log.Fatalf("%s%v%s", ColorRed, err, ColorReset)
 	}
 
-	if *daemon {
+	if *daemon && !*once {
 		// Ejecutar como daemon
 		app.runDaemon()
-	} else {
-		// Ejecutar una sola vez por defecto
-		if err := app.runOnce(); err != nil {
-			log.Fatalf("%sError running check: %v%s", ColorRed, err, ColorReset)
-		}
+		return
 	}
-}
 
-// App encapsula la lógica de la aplicación
-type App struct {
-	checker  *docker.Checker
-	notifier *notification.NotificationManager
-	config   *config.Config
+	// Ejecutar una sola vez (por defecto o con --once); el código de salida
+	// refleja los hallazgos según --fail-on
+	checkReport, err := app.RunCheck(context.Background())
+	if err != nil {
+		log.Fatalf("%sError running check: %v%s", ColorRed, err, ColorReset)
+	}
+	code := exitCode(checkReport, failOnRules)
+	dockerClient.Close()
+	shutdownTracing()
+	os.Exit(code)
 }
 
-// runOnce ejecuta la verificación una sola vez
-func (a *App) runOnce() error {
-	fmt.Printf("%s--- Iniciando verificación de imágenes Docker ---%s\n", ColorBlue, ColorReset)
-
-	ctx := context.Background()
-	report, err := a.checker.CheckAll(ctx)
+// loadConfig carga la configuración, valida los valores que solo conocen
+// i18n y report (config no depende de ellos) y aplica el idioma configurado
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.Load(path)
 	if err != nil {
-		return fmt.Errorf("failed to check containers: %w", err)
+		return nil, err
 	}
-
-	// Añadir información del hostname
-	hostname, _ := os.Hostname()
-	report.Hostname = hostname
-	report.Timestamp = time.Now()
-
-	// Mostrar resultados en consola
-	a.printReport(report)
-
-	// Enviar notificaciones si hay actualizaciones o errores
-	if len(report.Available) > 0 || len(report.Failed) > 0 {
-		fmt.Printf("📢 Enviando notificaciones (Actualizaciones: %d, Errores: %d)...\n", len(report.Available), len(report.Failed))
-
-		notificationData := &model.NotificationData{
-			Report:   report,
-			Hostname: hostname,
-		}
-
-		if err := a.notifier.NotifyAll(notificationData); err != nil {
-			fmt.Printf("%sWarning: Failed to send notifications: %v%s\n", ColorYellow, err, ColorReset)
-		} else {
-			fmt.Printf("✅ Notificaciones enviadas exitosamente\n")
+	if cfg.Language != "" && !i18n.Supported(cfg.Language) {
+		return nil, fmt.Errorf("invalid configuration: unsupported language %q (expected en or es)", cfg.Language)
+	}
+	for _, format := range cfg.Reports.Formats {
+		if _, err := report.ParseFormat(format); err != nil {
+			return nil, fmt.Errorf("invalid configuration: reports.formats: %w", err)
 		}
-	} else {
-		fmt.Printf("ℹ️  No hay actualizaciones ni errores, no se envían notificaciones\n")
 	}
-
-	fmt.Printf("%s--- Verificación completada ---%s\n", ColorBlue, ColorReset)
-	return nil
+	if err := i18n.SetLanguage(i18n.Resolve(cfg.Language)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-// runDaemon ejecuta la verificación de forma continua
-func (a *App) runDaemon() {
-	c := cron.New()
-
-	_, err := c.AddFunc(a.config.Checker.Schedule, func() {
-		if err := a.runOnce(); err != nil {
-			log.Printf("%sError running check: %v%s", ColorRed, err, ColorReset)
-		}
-	})
-	if err != nil {
-		log.Fatalf("%sInvalid cron schedule configuration: %v%s", ColorRed, err, ColorReset)
+// setupPublisher crea el publicador de reportes; nil si reports.dir está vacío
+func setupPublisher(cfg *config.Config) (*report.Publisher, error) {
+	if cfg.Reports.Dir == "" {
+		return nil, nil
 	}
 
-	fmt.Printf("%s--- Iniciando modo daemon (schedule: %s) ---%s\n", ColorBlue, a.config.Checker.Schedule, ColorReset)
-
-	// Canal para manejar señales de interrupción
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	c.Start()
-	defer c.Stop()
-
-	// Ejecutar primera verificación inmediatamente
-	if err := a.runOnce(); err != nil {
-		log.Printf("%sError in initial check: %v%s", ColorRed, err, ColorReset)
+	options := report.PublishOptions{
+		Dir:        cfg.Reports.Dir,
+		MaxBackups: cfg.Reports.MaxBackups,
+		Write:      report.WriteOptions{SARIFBaseDir: cfg.Reports.SARIFBaseDir},
 	}
-
-	// Esperar señal de interrupción
-	<-sigChan
-	fmt.Printf("%s--- Deteniendo daemon ---%s\n", ColorBlue, ColorReset)
-}
-
-// printReport muestra el reporte en consola con colores
-func (a *App) printReport(report *model.CheckReport) {
-	fmt.Printf("\n📊 Resumen:\n")
-	fmt.Printf("   - 🖥️  Host: %s\n", report.Hostname)
-	fmt.Printf("   - 🛟  Contenedores con actualizaciones disponibles: %s%d%s\n", ColorYellow, len(report.Available), ColorReset)
-	fmt.Printf("   - ✅  Contenedores verificados: %d\n", report.Total)
-	fmt.Printf("   - ❌  Fallidos: %s%d%s\n", ColorRed, len(report.Failed), ColorReset)
-
-	if len(report.Available) > 0 {
-		fmt.Printf("\n📦 Actualizaciones disponibles:\n")
-		for _, update := range report.Available {
-			fmt.Printf("   - 🔄 %s%s%s (%s)\n", ColorYellow, update.Container.Name, ColorReset, update.Container.ImageName)
-			fmt.Printf("     • Versión actual: %s\n", update.CurrentVersion)
-			fmt.Printf("     • Nueva versión: %s\n", update.LatestVersion)
+	for _, name := range cfg.Reports.Formats {
+		format, err := report.ParseFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reports.formats: %w", err)
 		}
+		options.Formats = append(options.Formats, format)
 	}
+	return report.NewPublisher(options)
+}
 
-	if len(report.Failed) > 0 {
-		fmt.Printf("\n🚫 Fallos en:\n")
-		for _, failed := range report.Failed {
-			fmt.Printf("   - %s%s%s (%s) ❌\n", ColorRed, failed.Container.Name, ColorReset, failed.Container.ImageName)
-			if failed.Error != nil {
-				fmt.Printf("     Error: %v\n", failed.Error)
-			}
-		}
+// setupTracing configura la exportación de trazas y devuelve la función que
+// envía las pendientes al terminar
+func setupTracing(cfg *config.Config) (func(), error) {
+	if !cfg.Tracing.Enabled {
+		return func() {}, nil
 	}
 
-	if len(report.UpToDate) > 0 {
-		fmt.Printf("\n✅ Actualizados (%d):\n", len(report.UpToDate))
-		for _, upToDate := range report.UpToDate {
-			fmt.Printf("   - %s%s%s (%s)\n", ColorGreen, upToDate.Container.Name, ColorReset, upToDate.Container.ImageName)
-		}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:    cfg.Tracing.Endpoint,
+		Headers:     cfg.Tracing.Headers,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
 	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("%sWarning: failed to export traces: %v%s", ColorYellow, err, ColorReset)
+		}
+	}, nil
 }
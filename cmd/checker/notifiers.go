@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/config"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/notification"
+)
+
+// notifierSet registra los notificadores creados bajo un nombre único
+type notifierSet struct {
+	manager *notification.NotificationManager
+	names   map[string]bool
+}
+
+// add suscribe un notificador con su nombre configurado o el nombre por
+// defecto y, si se indica, su propio timeout
+func (ns *notifierSet) add(name, defaultName string, timeout time.Duration, observer notification.Observer) error {
+	if name == "" {
+		name = defaultName
+	}
+	if ns.names[name] {
+		return fmt.Errorf("duplicate notifier name %q, set a unique name", name)
+	}
+	ns.names[name] = true
+	ns.manager.Subscribe(notification.WithName(name, observer))
+	ns.manager.SetTimeout(name, timeout)
+	return nil
+}
+
+// setupNotifications crea el manager de notificaciones con los notificadores
+// habilitados; los mensajes de progreso se escriben en progress
+func setupNotifications(cfg *config.Config, progress io.Writer) (*notification.NotificationManager, error) {
+	notifiers := &notifierSet{
+		manager: notification.NewNotificationManager(),
+		names:   make(map[string]bool),
+	}
+	notifiers.manager.SetDefaultTimeout(cfg.Notifications.Timeout)
+	notifiers.manager.SetProgress(progress)
+
+	if telegramCfg := cfg.Notifications.Telegram; telegramCfg.Enabled {
+		parseMode, err := notification.ParseTelegramParseMode(telegramCfg.ParseMode)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Telegram notifier: %w", err)
+		}
+		telegramNotifier, err := notification.NewTelegramNotifier(notification.TelegramOptions{
+			BotToken:           cfg.TelegramBotToken,
+			Chats:              telegramChats(cfg),
+			TemplatePath:       telegramCfg.TemplateFile,
+			EventTemplates:     eventTemplates(telegramCfg.Templates),
+			ParseMode:          parseMode,
+			SilentWhenUpToDate: telegramCfg.SilentWhenUpToDate,
+			DocumentThreshold:  telegramCfg.DocumentThreshold,
+			Progress:           progress,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating Telegram notifier: %w", err)
+		}
+		if err := notifiers.add(telegramCfg.Name, "telegram", telegramCfg.Timeout, telegramNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if ntfyCfg := cfg.Notifications.Ntfy; ntfyCfg.Enabled {
+		ntfyNotifier, err := notification.NewNtfyNotifier(notification.NtfyOptions{
+			TopicURL:       ntfyCfg.URL,
+			Token:          cfg.NtfyToken,
+			Priority:       ntfyCfg.Priority,
+			Tags:           ntfyCfg.Tags,
+			Click:          ntfyCfg.Click,
+			TemplatePath:   ntfyCfg.TemplateFile,
+			EventTemplates: eventTemplates(ntfyCfg.Templates),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating ntfy notifier: %w", err)
+		}
+		if err := notifiers.add(ntfyCfg.Name, "ntfy", ntfyCfg.Timeout, ntfyNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if gotifyCfg := cfg.Notifications.Gotify; gotifyCfg.Enabled {
+		gotifyNotifier, err := notification.NewGotifyNotifier(notification.GotifyOptions{
+			ServerURL:      gotifyCfg.URL,
+			AppToken:       cfg.GotifyToken,
+			Priority:       gotifyCfg.Priority,
+			Markdown:       gotifyCfg.Markdown,
+			TemplatePath:   gotifyCfg.TemplateFile,
+			EventTemplates: eventTemplates(gotifyCfg.Templates),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating gotify notifier: %w", err)
+		}
+		if err := notifiers.add(gotifyCfg.Name, "gotify", gotifyCfg.Timeout, gotifyNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if matrixCfg := cfg.Notifications.Matrix; matrixCfg.Enabled {
+		matrixNotifier, err := notification.NewMatrixNotifier(notification.MatrixOptions{
+			HomeserverURL:  matrixCfg.HomeserverURL,
+			RoomID:         matrixCfg.RoomID,
+			AccessToken:    cfg.MatrixToken,
+			TemplatePath:   matrixCfg.TemplateFile,
+			EventTemplates: eventTemplates(matrixCfg.Templates),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating matrix notifier: %w", err)
+		}
+		if err := notifiers.add(matrixCfg.Name, "matrix", matrixCfg.Timeout, matrixNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if mqttCfg := cfg.Notifications.MQTT; mqttCfg.Enabled {
+		mqttNotifier, err := notification.NewMQTTNotifier(notification.MQTTOptions{
+			BrokerURL:          mqttCfg.Broker,
+			ClientID:           mqttCfg.ClientID,
+			Username:           mqttCfg.Username,
+			Password:           cfg.MQTTPassword,
+			TopicPrefix:        mqttCfg.TopicPrefix,
+			QoS:                mqttCfg.QoS,
+			Discovery:          mqttCfg.Discovery,
+			DiscoveryPrefix:    mqttCfg.DiscoveryPrefix,
+			CAFile:             mqttCfg.TLS.CAFile,
+			CertFile:           mqttCfg.TLS.CertFile,
+			KeyFile:            mqttCfg.TLS.KeyFile,
+			InsecureSkipVerify: mqttCfg.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating mqtt notifier: %w", err)
+		}
+		if err := notifiers.add(mqttCfg.Name, "mqtt", mqttCfg.Timeout, mqttNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if syslogCfg := cfg.Notifications.Syslog; syslogCfg.Enabled {
+		syslogNotifier, err := notification.NewSyslogNotifier(notification.SyslogOptions{
+			Network:  syslogCfg.Network,
+			Address:  syslogCfg.Address,
+			Facility: syslogCfg.Facility,
+			AppName:  syslogCfg.AppName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating syslog notifier: %w", err)
+		}
+		if err := notifiers.add(syslogCfg.Name, "syslog", syslogCfg.Timeout, syslogNotifier); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, execCfg := range cfg.Notifications.Exec {
+		execNotifier, err := notification.NewExecNotifier(notification.ExecOptions{
+			Command:        execCfg.Command,
+			Env:            execCfg.Env,
+			Dir:            execCfg.Dir,
+			TemplatePath:   execCfg.TemplateFile,
+			EventTemplates: eventTemplates(execCfg.Templates),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating notifier from notifications.exec[%d]: %w", i, err)
+		}
+		if err := notifiers.add(execCfg.Name, "exec", execCfg.Timeout, execNotifier); err != nil {
+			return nil, fmt.Errorf("notifications.exec[%d]: %w", i, err)
+		}
+	}
+
+	for i, notifierURL := range cfg.Notifications.URLs {
+		observer, err := notification.NewFromURL(notifierURL.URL)
+		if err != nil {
+			return nil, fmt.Errorf("error creating notifier from notifications.urls[%d]: %w", i, err)
+		}
+		if err := notifiers.add(notifierURL.Name, notification.ServiceName(notifierURL.URL), notifierURL.Timeout, observer); err != nil {
+			return nil, fmt.Errorf("notifications.urls[%d]: %w", i, err)
+		}
+	}
+
+	if len(cfg.Notifications.Routes) > 0 {
+		router, err := notification.NewRouter(buildRoutes(cfg.Notifications.Routes))
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification routes: %w", err)
+		}
+		for _, name := range router.Notifiers() {
+			if !notifiers.names[name] {
+				return nil, fmt.Errorf("notification route references unknown notifier %q", name)
+			}
+		}
+		notifiers.manager.SetRouter(router)
+	}
+
+	if outboxCfg := cfg.Notifications.Outbox; outboxCfg.Enabled {
+		outbox, err := notification.NewOutbox(notification.OutboxOptions{
+			Dir:        outboxCfg.Dir,
+			MaxAge:     outboxCfg.MaxAge,
+			MinBackoff: outboxCfg.MinBackoff,
+			MaxBackoff: outboxCfg.MaxBackoff,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating notification outbox: %w", err)
+		}
+		notifiers.manager.SetOutbox(outbox)
+	}
+
+	return notifiers.manager, nil
+}
+
+// setupThrottle crea el control de envío si hay alguna regla configurada;
+// devuelve nil si no la hay
+func setupThrottle(cfg *config.Config, manager *notification.NotificationManager, progress io.Writer) (*notification.Throttle, error) {
+	throttleCfg := cfg.Notifications.Throttle
+	if !throttleCfg.Enabled() {
+		return nil, nil
+	}
+
+	options := notification.ThrottleOptions{
+		RateLimits:       make(map[string]notification.RateLimit),
+		CollapseFailures: throttleCfg.CollapseFailures,
+		StateFile:        throttleCfg.StateFile,
+		Progress:         progress,
+	}
+	if quiet := throttleCfg.QuietHours; quiet.Start != "" {
+		quietHours, err := notification.ParseQuietHours(quiet.Start, quiet.End, quiet.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notifications.throttle.quiet_hours: %w", err)
+		}
+		options.QuietHours = quietHours
+	}
+
+	names := make(map[string]bool)
+	for _, observer := range manager.Observers() {
+		names[notification.ObserverName(observer)] = true
+	}
+	for name, limit := range throttleCfg.RateLimits {
+		if !names[name] {
+			return nil, fmt.Errorf("notifications.throttle.rate_limits references unknown notifier %q", name)
+		}
+		options.RateLimits[name] = notification.RateLimit{Max: limit.Max, Per: limit.Per}
+	}
+
+	throttle, err := notification.NewThrottle(manager, options)
+	if err != nil {
+		return nil, fmt.Errorf("error creating notification throttle: %w", err)
+	}
+	return throttle, nil
+}
+
+// telegramChats devuelve los chats configurados o, si no hay, TELEGRAM_CHAT_ID
+func telegramChats(cfg *config.Config) []notification.TelegramChat {
+	if len(cfg.Notifications.Telegram.Chats) == 0 {
+		return []notification.TelegramChat{{ChatID: cfg.TelegramChatID}}
+	}
+
+	chats := make([]notification.TelegramChat, 0, len(cfg.Notifications.Telegram.Chats))
+	for _, chat := range cfg.Notifications.Telegram.Chats {
+		chats = append(chats, notification.TelegramChat{
+			ChatID:              chat.ChatID,
+			MessageThreadID:     chat.MessageThreadID,
+			DisableNotification: chat.DisableNotification,
+		})
+	}
+	return chats
+}
+
+// eventTemplates convierte las plantillas por evento de la configuración
+func eventTemplates(paths map[string]string) map[model.EventType]string {
+	if len(paths) == 0 {
+		return nil
+	}
+	result := make(map[model.EventType]string, len(paths))
+	for event, path := range paths {
+		result[model.EventType(event)] = path
+	}
+	return result
+}
+
+// buildRoutes convierte las rutas de la configuración al formato del router
+func buildRoutes(routes []config.RouteConfig) []notification.Route {
+	result := make([]notification.Route, 0, len(routes))
+	for _, route := range routes {
+		match := notification.Match{
+			Containers:      route.Match.Containers,
+			Images:          route.Match.Images,
+			Labels:          route.Match.Labels,
+			ComposeProjects: route.Match.ComposeProjects,
+		}
+		for _, status := range route.Match.Status {
+			match.Statuses = append(match.Statuses, model.UpdateStatus(status))
+		}
+		for _, bump := range route.Match.Bump {
+			match.Bumps = append(match.Bumps, model.BumpLevel(bump))
+		}
+
+		result = append(result, notification.Route{
+			Name:      route.Name,
+			Notifiers: route.Notifiers,
+			Match:     match,
+		})
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/api"
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/metrics"
+)
+
+const (
+	defaultHTTPListen  = ":9120"
+	defaultMetricsPath = "/metrics"
+	// httpShutdownTimeout espera a las peticiones en curso al parar el daemon
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// newHTTPHandler enruta los endpoints activos del servidor HTTP; ctx cancela
+// las verificaciones lanzadas desde la API
+func (a *App) newHTTPHandler(ctx context.Context) (http.Handler, error) {
+	mux := http.NewServeMux()
+	if metricsCfg := a.config.HTTP.Metrics; metricsCfg.Enabled {
+		path := metricsCfg.Path
+		if path == "" {
+			path = defaultMetricsPath
+		}
+		mux.Handle(path, metrics.Handler())
+	}
+	if apiCfg := a.config.HTTP.API; apiCfg.Enabled {
+		server, err := api.NewServer(api.Options{
+			Token:   a.config.APIToken,
+			MaxRuns: apiCfg.MaxRuns,
+		}, a)
+		if err != nil {
+			return nil, err
+		}
+		server.Register(ctx, mux)
+	}
+	return mux, nil
+}
+
+// startHTTPServer arranca el servidor HTTP del daemon y lo para al cancelar ctx
+func (a *App) startHTTPServer(ctx context.Context) error {
+	listen := a.config.HTTP.Listen
+	if listen == "" {
+		listen = defaultHTTPListen
+	}
+
+	handler, err := a.newHTTPHandler(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Se escucha antes de volver para que un puerto ocupado sea un error de arranque
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("%sHTTP server stopped: %v%s", ColorRed, err, ColorReset)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintln(a.progress, i18n.T("daemon.http", listener.Addr()))
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/config"
+	"github.com/pablopin/docker-image-checker/internal/docker"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/notification"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// remoteDigest digest del registro, distinto del de la imagen local
+const remoteDigest = "sha256:2c3a5e5f3c4b1a2e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e"
+
+// fakeDockerAPI simula el daemon de Docker con un contenedor nginx:1.25.3
+// cuya imagen no coincide con el digest del registro
+func fakeDockerAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.43")
+		w.Header().Set("Content-Type", "application/json")
+		switch path := r.URL.Path; {
+		case path == "/_ping":
+			io.WriteString(w, "OK")
+		case strings.HasSuffix(path, "/containers/json"):
+			json.NewEncoder(w).Encode([]map[string]any{{
+				"Id": "abc123", "Names": []string{"/web"}, "Image": "nginx:1.25.3",
+				"ImageID": "sha256:local", "Status": "Up 2 hours",
+			}})
+		case strings.HasSuffix(path, "/images/sha256:local/json"):
+			json.NewEncoder(w).Encode(map[string]any{"Id": "sha256:local", "RepoTags": []string{"nginx:1.25.3"}})
+		case strings.HasSuffix(path, "/distribution/nginx:1.25.3/json"):
+			json.NewEncoder(w).Encode(map[string]any{"Descriptor": map[string]any{
+				"mediaType": "application/vnd.oci.image.index.v1+json", "digest": remoteDigest, "size": 1024,
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dockerHubTransport responde las peticiones a Docker Hub sin salir a la red
+type dockerHubTransport struct {
+	base http.RoundTripper
+}
+
+func (t dockerHubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "registry.hub.docker.com" {
+		return t.base.RoundTrip(req)
+	}
+	recorder := httptest.NewRecorder()
+	json.NewEncoder(recorder).Encode(map[string]any{"results": []map[string]any{
+		{"name": "1.25.4", "last_updated": time.Now().Format(time.RFC3339)},
+	}})
+	return recorder.Result(), nil
+}
+
+// nopObserver notificador que no envía nada
+type nopObserver struct{}
+
+func (nopObserver) Notify(context.Context, *model.NotificationData) error { return nil }
+
+func TestRunCheckSpanTree(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(fakeDockerAPI(t).URL, "http://"))
+	base := http.DefaultTransport
+	http.DefaultTransport = dockerHubTransport{base: base}
+	t.Cleanup(func() { http.DefaultTransport = base })
+
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.SetupWithExporter(exporter, tracing.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	client, err := docker.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	manager := notification.NewNotificationManager()
+	manager.SetProgress(io.Discard)
+	manager.Subscribe(notification.WithName("ops", nopObserver{}))
+	app := NewApp(docker.NewChecker(client), manager, nil, &config.Config{}, io.Discard)
+
+	report, err := app.RunCheck(context.Background())
+	if err != nil {
+		t.Fatalf("RunCheck: %v", err)
+	}
+	if len(report.Available) != 1 || report.Available[0].LatestVersion != "1.25.4" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// Padre de cada span por nombre; cada nombre aparece una sola vez
+	spans := exporter.GetSpans()
+	names := make(map[string]string)
+	parents := make(map[string]string)
+	for _, span := range spans {
+		names[span.SpanContext.SpanID().String()] = span.Name
+	}
+	for _, span := range spans {
+		if _, dup := parents[span.Name]; dup {
+			t.Errorf("span %s recorded more than once", span.Name)
+		}
+		parents[span.Name] = names[span.Parent.SpanID().String()]
+	}
+
+	want := map[string]string{
+		"App.RunCheck":                 "",
+		"Checker.CheckAll":             "App.RunCheck",
+		"CheckStrategy.Check":          "Checker.CheckAll",
+		"registry.DistributionInspect": "CheckStrategy.Check",
+		"HTTP GET":                     "CheckStrategy.Check",
+		"Observer.Notify":              "App.RunCheck",
+	}
+	for name, parent := range want {
+		got, ok := parents[name]
+		if !ok {
+			t.Errorf("missing span %s", name)
+			continue
+		}
+		if got != parent {
+			t.Errorf("span %s has parent %q, want %q", name, got, parent)
+		}
+	}
+
+	for _, span := range spans {
+		if span.Name == "Observer.Notify" && !hasAttribute(span.Attributes, attribute.String("notifier.name", "ops")) {
+			t.Errorf("Observer.Notify attributes %v, want notifier.name=ops", span.Attributes)
+		}
+		if span.Name == "HTTP GET" && !hasAttribute(span.Attributes, attribute.Int("http.response.status_code", http.StatusOK)) {
+			t.Errorf("HTTP GET attributes %v, want status code 200", span.Attributes)
+		}
+	}
+}
+
+// hasAttribute indica si la lista contiene el atributo con el mismo valor
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
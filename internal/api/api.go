@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/report"
+)
+
+// defaultMaxRuns ejecuciones que se recuerdan para GET /api/v1/checks/{id}
+const defaultMaxRuns = 50
+
+// Handler acciones de la aplicación que expone la API
+type Handler interface {
+	// RunCheck ejecuta una verificación completa y devuelve el reporte
+	RunCheck(ctx context.Context) (*model.CheckReport, error)
+	// LastReport devuelve el último reporte o nil si aún no hay ninguno
+	LastReport() *model.CheckReport
+}
+
+// Options configuración de la API HTTP
+type Options struct {
+	// Token token Bearer exigido en /api/v1
+	Token string
+	// MaxRuns ejecuciones terminadas que se conservan (por defecto 50)
+	MaxRuns int
+}
+
+// RunStatus estado de una ejecución lanzada desde la API
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// Run ejecución lanzada con POST /api/v1/checks
+type Run struct {
+	ID         string             `json:"id"`
+	Status     RunStatus          `json:"status"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Report     *model.CheckReport `json:"report,omitempty"`
+}
+
+// Server API REST para lanzar verificaciones y consultar reportes. Solo
+// admite una ejecución a la vez.
+type Server struct {
+	options Options
+	handler Handler
+
+	mu     sync.Mutex
+	runs   map[string]*Run
+	order  []string
+	active string
+}
+
+// NewServer crea la API
+func NewServer(options Options, handler Handler) (*Server, error) {
+	if options.Token == "" {
+		return nil, fmt.Errorf("api token is required")
+	}
+	if options.MaxRuns <= 0 {
+		options.MaxRuns = defaultMaxRuns
+	}
+	return &Server{
+		options: options,
+		handler: handler,
+		runs:    make(map[string]*Run),
+	}, nil
+}
+
+// Register añade las rutas al mux; las ejecuciones lanzadas desde la API se
+// cancelan con ctx
+func (s *Server) Register(ctx context.Context, mux *http.ServeMux) {
+	mux.Handle("POST /api/v1/checks", s.authorize(func(w http.ResponseWriter, r *http.Request) {
+		s.startCheck(ctx, w, r)
+	}))
+	mux.Handle("GET /api/v1/checks/latest", s.authorize(s.latest))
+	mux.Handle("GET /api/v1/checks/{id}", s.authorize(s.getRun))
+	mux.Handle("GET /api/v1/containers", s.authorize(s.containers))
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+}
+
+// authorize exige el token Bearer configurado
+func (s *Server) authorize(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.options.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="docker-image-checker"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// startCheck lanza una verificación en segundo plano y devuelve su ID. Si ya
+// hay una en curso responde 409 con el ID de esa ejecución.
+func (s *Server) startCheck(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	id, err := newRunID()
+	if err != nil {
+		log.Printf("API check not started: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to start check")
+		return
+	}
+
+	s.mu.Lock()
+	if s.active != "" {
+		active := s.snapshot(s.active)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error": "a check is already running",
+			"run":   active,
+		})
+		return
+	}
+
+	run := &Run{ID: id, Status: RunRunning, StartedAt: time.Now()}
+	s.runs[run.ID] = run
+	s.order = append(s.order, run.ID)
+	s.active = run.ID
+	s.prune()
+	accepted := *run
+	s.mu.Unlock()
+
+	go s.execute(ctx, run.ID)
+
+	w.Header().Set("Location", "/api/v1/checks/"+run.ID)
+	writeJSON(w, http.StatusAccepted, accepted)
+}
+
+// execute ejecuta la verificación y guarda el resultado de la ejecución
+func (s *Server) execute(ctx context.Context, id string) {
+	checkReport, err := s.handler.RunCheck(ctx)
+	if err != nil {
+		log.Printf("API check %s failed: %v", id, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.runs[id]
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Report = checkReport
+	run.Status = RunSucceeded
+	if err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+	}
+	s.active = ""
+	s.prune()
+}
+
+// prune olvida las ejecuciones terminadas más antiguas por encima de MaxRuns
+func (s *Server) prune() {
+	for len(s.order) > s.options.MaxRuns {
+		oldest := s.order[0]
+		if oldest == s.active {
+			return
+		}
+		delete(s.runs, oldest)
+		s.order = s.order[1:]
+	}
+}
+
+// snapshot copia una ejecución para serializarla fuera del lock
+func (s *Server) snapshot(id string) *Run {
+	run, ok := s.runs[id]
+	if !ok {
+		return nil
+	}
+	copied := *run
+	return &copied
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	run := s.snapshot(r.PathValue("id"))
+	s.mu.Unlock()
+
+	if run == nil {
+		writeError(w, http.StatusNotFound, "check run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// latest devuelve el último reporte, lo haya lanzado la API, el cron o el bot
+func (s *Server) latest(w http.ResponseWriter, r *http.Request) {
+	last := s.handler.LastReport()
+	if last == nil {
+		writeError(w, http.StatusNotFound, "no check has completed yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, last)
+}
+
+// containers devuelve el estado de cada contenedor según el último reporte
+func (s *Server) containers(w http.ResponseWriter, r *http.Request) {
+	last := s.handler.LastReport()
+	if last == nil {
+		writeError(w, http.StatusNotFound, "no check has completed yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, report.NewDocument(last))
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz está listo cuando ha terminado la primera verificación
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if s.handler.LastReport() == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "waiting for the first check"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// newRunID genera un identificador aleatorio de ejecución
+func newRunID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate run ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
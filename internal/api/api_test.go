@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const testToken = "s3cret"
+
+// fakeHandler bloquea cada RunCheck hasta recibir en release y guarda el
+// reporte como último
+type fakeHandler struct {
+	release chan struct{}
+
+	mu   sync.Mutex
+	last *model.CheckReport
+}
+
+func (h *fakeHandler) RunCheck(ctx context.Context) (*model.CheckReport, error) {
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	report := &model.CheckReport{Hostname: "host", Timestamp: time.Now()}
+	h.mu.Lock()
+	h.last = report
+	h.mu.Unlock()
+	return report, nil
+}
+
+func (h *fakeHandler) LastReport() *model.CheckReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// newTestServer arranca la API sobre httptest con un fakeHandler
+func newTestServer(t *testing.T, options Options) (*httptest.Server, *fakeHandler) {
+	t.Helper()
+	handler := &fakeHandler{release: make(chan struct{})}
+	options.Token = testToken
+	server, err := NewServer(options, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mux := http.NewServeMux()
+	server.Register(ctx, mux)
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		cancel()
+		httpServer.Close()
+	})
+	return httpServer, handler
+}
+
+// do envía una petición con el token indicado (vacío = sin cabecera) y
+// decodifica la respuesta JSON en out si no es nil
+func do(t *testing.T, server *httptest.Server, method, path, authorization string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("%s %s: 401 without WWW-Authenticate", method, path)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// startRun lanza una verificación y devuelve su ID
+func startRun(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	var run Run
+	if code := do(t, server, http.MethodPost, "/api/v1/checks", "Bearer "+testToken, &run); code != http.StatusAccepted {
+		t.Fatalf("POST /api/v1/checks = %d, want 202", code)
+	}
+	return run.ID
+}
+
+// waitRun espera a que la ejecución termine y la devuelve
+func waitRun(t *testing.T, server *httptest.Server, id string) Run {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var run Run
+		if code := do(t, server, http.MethodGet, "/api/v1/checks/"+id, "Bearer "+testToken, &run); code != http.StatusOK {
+			t.Fatalf("GET check %s = %d, want 200", id, code)
+		}
+		if run.Status != RunRunning {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("check %s still running", id)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/checks"},
+		{http.MethodGet, "/api/v1/checks/latest"},
+		{http.MethodGet, "/api/v1/checks/abc"},
+		{http.MethodGet, "/api/v1/containers"},
+	}
+	for _, authorization := range []string{"", "Bearer", "Bearer ", "Bearer wrong", "Bearer " + testToken + "x", "Basic " + testToken} {
+		for _, route := range routes {
+			if code := do(t, server, route.method, route.path, authorization, nil); code != http.StatusUnauthorized {
+				t.Errorf("%s %s with %q = %d, want 401", route.method, route.path, authorization, code)
+			}
+		}
+	}
+
+	// Con el token correcto se llega al handler (404 porque aún no hay datos)
+	if code := do(t, server, http.MethodGet, "/api/v1/checks/latest", "Bearer "+testToken, nil); code != http.StatusNotFound {
+		t.Errorf("GET latest with valid token = %d, want 404", code)
+	}
+	// Las sondas no requieren token
+	if code := do(t, server, http.MethodGet, "/healthz", "", nil); code != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want 200", code)
+	}
+}
+
+func TestConcurrentCheckConflict(t *testing.T) {
+	server, handler := newTestServer(t, Options{})
+
+	id := startRun(t, server)
+	var conflict struct {
+		Error string `json:"error"`
+		Run   *Run   `json:"run"`
+	}
+	if code := do(t, server, http.MethodPost, "/api/v1/checks", "Bearer "+testToken, &conflict); code != http.StatusConflict {
+		t.Fatalf("second POST = %d, want 409", code)
+	}
+	if conflict.Run == nil || conflict.Run.ID != id || conflict.Run.Status != RunRunning {
+		t.Errorf("409 body run = %+v, want running %s", conflict.Run, id)
+	}
+
+	handler.release <- struct{}{}
+	if run := waitRun(t, server, id); run.Status != RunSucceeded || run.Report == nil {
+		t.Errorf("finished run = %+v, want succeeded with report", run)
+	}
+
+	// Terminada la anterior se admite una nueva
+	next := startRun(t, server)
+	if next == id {
+		t.Errorf("run ID %s reused", id)
+	}
+	handler.release <- struct{}{}
+	waitRun(t, server, next)
+}
+
+func TestRunPruning(t *testing.T) {
+	server, handler := newTestServer(t, Options{MaxRuns: 2})
+
+	var ids []string
+	for range 3 {
+		id := startRun(t, server)
+		handler.release <- struct{}{}
+		waitRun(t, server, id)
+		ids = append(ids, id)
+	}
+
+	if code := do(t, server, http.MethodGet, "/api/v1/checks/"+ids[0], "Bearer "+testToken, nil); code != http.StatusNotFound {
+		t.Errorf("oldest run = %d, want 404 after pruning", code)
+	}
+	for _, id := range ids[1:] {
+		if code := do(t, server, http.MethodGet, "/api/v1/checks/"+id, "Bearer "+testToken, nil); code != http.StatusOK {
+			t.Errorf("run %s = %d, want 200", id, code)
+		}
+	}
+}
+
+func TestReadyzBeforeFirstCheck(t *testing.T) {
+	server, handler := newTestServer(t, Options{})
+
+	var body map[string]string
+	if code := do(t, server, http.MethodGet, "/readyz", "", &body); code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz before first check = %d, want 503", code)
+	}
+	if code := do(t, server, http.MethodGet, "/api/v1/containers", "Bearer "+testToken, nil); code != http.StatusNotFound {
+		t.Errorf("containers before first check = %d, want 404", code)
+	}
+
+	id := startRun(t, server)
+	handler.release <- struct{}{}
+	waitRun(t, server, id)
+
+	if code := do(t, server, http.MethodGet, "/readyz", "", &body); code != http.StatusOK || body["status"] != "ready" {
+		t.Errorf("readyz after check = %d %v, want 200 ready", code, body)
+	}
+	if code := do(t, server, http.MethodGet, "/api/v1/checks/latest", "Bearer "+testToken, nil); code != http.StatusOK {
+		t.Errorf("latest after check = %d, want 200", code)
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/robfig/cron/v3"
@@ -14,10 +17,24 @@ type Config struct {
 	Checker       CheckerConfig       `yaml:"checker"`
 	Notifications NotificationsConfig `yaml:"notifications"`
 	Logging       LoggingConfig       `yaml:"logging"`
-	
+	// Reports publica el reporte de cada ejecución en un directorio
+	Reports ReportsConfig `yaml:"reports"`
+	// HTTP servidor del modo daemon (métricas de Prometheus y API REST)
+	HTTP HTTPConfig `yaml:"http"`
+	// Tracing exporta trazas de OpenTelemetry por OTLP/HTTP
+	Tracing TracingConfig `yaml:"tracing"`
+	// Language idioma de la consola y las plantillas por defecto (en, es);
+	// vacío usa LANG
+	Language string `yaml:"language"`
+
 	// Variables de entorno
 	TelegramBotToken string
 	TelegramChatID   string
+	NtfyToken        string
+	GotifyToken      string
+	MatrixToken      string
+	MQTTPassword     string
+	APIToken         string
 	DockerHost       string
 	LogLevel         string
 }
@@ -32,12 +49,226 @@ type CheckerConfig struct {
 // NotificationsConfig configuración de notificaciones
 type NotificationsConfig struct {
 	Telegram TelegramConfig `yaml:"telegram"`
+	Ntfy     NtfyConfig     `yaml:"ntfy"`
+	Gotify   GotifyConfig   `yaml:"gotify"`
+	Matrix   MatrixConfig   `yaml:"matrix"`
+	MQTT     MQTTConfig     `yaml:"mqtt"`
+	Syslog   SyslogConfig   `yaml:"syslog"`
+	// Exec comandos externos que reciben el reporte en JSON por stdin
+	Exec []ExecConfig `yaml:"exec"`
+
+	// Timeout tiempo máximo por notificador salvo que tenga uno propio
+	Timeout time.Duration `yaml:"timeout"`
+
+	// URLs de servicio (ej: "telegram://token@telegram?chats=123")
+	URLs []NotifierURL `yaml:"urls"`
+
+	// Routes envía a notificadores concretos solo los contenedores que les interesan
+	Routes []RouteConfig `yaml:"routes"`
+
+	// StateFile guarda lo último notificado; vacío notifica en cada ejecución
+	StateFile string `yaml:"state_file"`
+	// ReminderDays reenvía los pendientes cada N días (0 = nunca)
+	ReminderDays int `yaml:"reminder_days"`
+
+	// Outbox guarda las notificaciones en disco y las reintenta hasta entregarlas
+	Outbox OutboxConfig `yaml:"outbox"`
+
+	// Throttle horas de silencio, límites de envío y agrupación de fallos
+	Throttle ThrottleConfig `yaml:"throttle"`
+}
+
+// ThrottleConfig configuración del control de envío de notificaciones
+type ThrottleConfig struct {
+	// StateFile conserva lo retenido entre ejecuciones; vacío = solo en memoria
+	StateFile  string           `yaml:"state_file"`
+	QuietHours QuietHoursConfig `yaml:"quiet_hours"`
+	// RateLimits límite por nombre de notificador
+	RateLimits map[string]RateLimitConfig `yaml:"rate_limits"`
+	// CollapseFailures agrupa las notificaciones de solo fallos durante esta ventana
+	CollapseFailures time.Duration `yaml:"collapse_failures"`
+}
+
+// Enabled indica si hay alguna regla de control de envío configurada
+func (t ThrottleConfig) Enabled() bool {
+	return t.QuietHours.Start != "" || len(t.RateLimits) > 0 || t.CollapseFailures > 0
+}
+
+// QuietHoursConfig franja horaria "HH:MM" en la que se retienen las notificaciones no críticas
+type QuietHoursConfig struct {
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	Timezone string `yaml:"timezone"`
+}
+
+// RateLimitConfig número máximo de notificaciones por periodo
+type RateLimitConfig struct {
+	Max int           `yaml:"max"`
+	Per time.Duration `yaml:"per"`
+}
+
+// OutboxConfig configuración del outbox persistente de notificaciones
+type OutboxConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Dir        string        `yaml:"dir"`
+	MaxAge     time.Duration `yaml:"max_age"`
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
 }
 
 // TelegramConfig configuración específica de Telegram
 type TelegramConfig struct {
-	Enabled      bool   `yaml:"enabled"`
-	TemplateFile string `yaml:"template_file"`
+	Name         string        `yaml:"name"`
+	Enabled      bool          `yaml:"enabled"`
+	Timeout      time.Duration `yaml:"timeout"`
+	TemplateFile string        `yaml:"template_file"`
+	// Templates plantilla por evento (updates, failures, recovery)
+	Templates map[string]string `yaml:"templates"`
+	// ParseMode HTML (por defecto), MarkdownV2 o None
+	ParseMode string `yaml:"parse_mode"`
+	// Chats destinos; si está vacío se usa TELEGRAM_CHAT_ID
+	Chats              []TelegramChatConfig `yaml:"chats"`
+	SilentWhenUpToDate bool                 `yaml:"silent_when_up_to_date"`
+	// DocumentThreshold adjunta el reporte como fichero a partir de N caracteres (0 = nunca)
+	DocumentThreshold int `yaml:"document_threshold"`
+	// Bot atiende comandos (/check, /status, /ignore) en modo daemon
+	Bot TelegramBotConfig `yaml:"bot"`
+}
+
+// TelegramBotConfig configuración del bot de comandos de Telegram
+type TelegramBotConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	AllowedChatIDs []int64       `yaml:"allowed_chat_ids"`
+	AllowedUserIDs []int64       `yaml:"allowed_user_ids"`
+	PollTimeout    time.Duration `yaml:"poll_timeout"`
+}
+
+// TelegramChatConfig destino de Telegram, opcionalmente un tema de un foro
+type TelegramChatConfig struct {
+	ChatID              string `yaml:"chat_id"`
+	MessageThreadID     int    `yaml:"message_thread_id"`
+	DisableNotification bool   `yaml:"disable_notification"`
+}
+
+// NtfyConfig configuración específica de ntfy
+type NtfyConfig struct {
+	Name         string            `yaml:"name"`
+	Enabled      bool              `yaml:"enabled"`
+	Timeout      time.Duration     `yaml:"timeout"`
+	URL          string            `yaml:"url"`
+	Priority     int               `yaml:"priority"`
+	Tags         []string          `yaml:"tags"`
+	Click        string            `yaml:"click"`
+	TemplateFile string            `yaml:"template_file"`
+	Templates    map[string]string `yaml:"templates"`
+}
+
+// GotifyConfig configuración específica de Gotify
+type GotifyConfig struct {
+	Name         string            `yaml:"name"`
+	Enabled      bool              `yaml:"enabled"`
+	Timeout      time.Duration     `yaml:"timeout"`
+	URL          string            `yaml:"url"`
+	Priority     int               `yaml:"priority"`
+	Markdown     bool              `yaml:"markdown"`
+	TemplateFile string            `yaml:"template_file"`
+	Templates    map[string]string `yaml:"templates"`
+}
+
+// MatrixConfig configuración específica de Matrix
+type MatrixConfig struct {
+	Name          string            `yaml:"name"`
+	Enabled       bool              `yaml:"enabled"`
+	Timeout       time.Duration     `yaml:"timeout"`
+	HomeserverURL string            `yaml:"homeserver_url"`
+	RoomID        string            `yaml:"room_id"`
+	TemplateFile  string            `yaml:"template_file"`
+	Templates     map[string]string `yaml:"templates"`
+}
+
+// MQTTConfig configuración específica de MQTT; la contraseña se lee de MQTT_PASSWORD
+type MQTTConfig struct {
+	Name     string        `yaml:"name"`
+	Enabled  bool          `yaml:"enabled"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Broker   string        `yaml:"broker"`
+	ClientID string        `yaml:"client_id"`
+	Username string        `yaml:"username"`
+	// TopicPrefix prefijo de los topics de estado (por defecto docker-image-checker)
+	TopicPrefix string `yaml:"topic_prefix"`
+	QoS         int    `yaml:"qos"`
+	// Discovery publica las entidades update de Home Assistant
+	Discovery       bool          `yaml:"discovery"`
+	DiscoveryPrefix string        `yaml:"discovery_prefix"`
+	TLS             MQTTTLSConfig `yaml:"tls"`
+}
+
+// MQTTTLSConfig certificados para conexiones mqtts://
+type MQTTTLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// SyslogConfig configuración específica de syslog
+type SyslogConfig struct {
+	Name    string        `yaml:"name"`
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Network udp, tcp, unix o vacío para el socket local (/dev/log)
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`
+	Facility string `yaml:"facility"`
+	AppName  string `yaml:"app_name"`
+}
+
+// ExecConfig comando externo usado como notificador
+type ExecConfig struct {
+	Name    string        `yaml:"name"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Command programa y argumentos, sin shell
+	Command      []string          `yaml:"command"`
+	Env          map[string]string `yaml:"env"`
+	Dir          string            `yaml:"dir"`
+	TemplateFile string            `yaml:"template_file"`
+	Templates    map[string]string `yaml:"templates"`
+}
+
+// NotifierURL URL de servicio de un notificador. En YAML puede escribirse
+// como un string o como un mapa con name y url.
+type NotifierURL struct {
+	Name    string        `yaml:"name"`
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// UnmarshalYAML admite tanto la forma corta (string) como la extendida
+func (n *NotifierURL) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		n.Name = ""
+		return value.Decode(&n.URL)
+	}
+
+	type plain NotifierURL
+	return value.Decode((*plain)(n))
+}
+
+// RouteConfig regla de enrutado de notificaciones
+type RouteConfig struct {
+	Name      string      `yaml:"name"`
+	Notifiers []string    `yaml:"notifiers"`
+	Match     MatchConfig `yaml:"match"`
+}
+
+// MatchConfig criterios de una regla de enrutado; admiten comodines (* y ?)
+type MatchConfig struct {
+	Containers      []string          `yaml:"containers"`
+	Images          []string          `yaml:"images"`
+	Labels          map[string]string `yaml:"labels"`
+	ComposeProjects []string          `yaml:"compose_projects"`
+	Status          []string          `yaml:"status"`
+	Bump            []string          `yaml:"bump"`
 }
 
 // LoggingConfig configuración de logging
@@ -47,12 +278,63 @@ type LoggingConfig struct {
 	MaxBackups int    `yaml:"max_backups"`
 }
 
+// ReportsConfig publicación de reportes en disco; vacío Dir la desactiva
+type ReportsConfig struct {
+	Dir string `yaml:"dir"`
+	// Formats formatos publicados (por defecto html y markdown)
+	Formats []string `yaml:"formats"`
+	// MaxBackups reportes archivados por formato que se conservan (0 = todos)
+	MaxBackups int `yaml:"max_backups"`
+	// SARIFBaseDir ruta absoluta, en el host de Docker, del repositorio con
+	// los ficheros de Compose; las ubicaciones SARIF se emiten relativas a él
+	SARIFBaseDir string `yaml:"sarif_base_dir"`
+}
+
+// HTTPConfig servidor HTTP del modo daemon; solo se arranca si sirve algo
+type HTTPConfig struct {
+	// Listen dirección de escucha (por defecto :9120)
+	Listen  string        `yaml:"listen"`
+	Metrics MetricsConfig `yaml:"metrics"`
+	API     APIConfig     `yaml:"api"`
+}
+
+// Enabled indica si el servidor HTTP tiene algún endpoint activo
+func (h HTTPConfig) Enabled() bool {
+	return h.Metrics.Enabled || h.API.Enabled
+}
+
+// APIConfig API REST para lanzar verificaciones y leer reportes; el token
+// Bearer se lee de API_TOKEN
+type APIConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxRuns ejecuciones lanzadas por la API que se recuerdan (por defecto 50)
+	MaxRuns int `yaml:"max_runs"`
+}
+
+// MetricsConfig endpoint de métricas de Prometheus
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path ruta del endpoint (por defecto /metrics)
+	Path string `yaml:"path"`
+}
+
+// TracingConfig exportación de trazas de OpenTelemetry
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint URL del colector OTLP/HTTP; vacío usa OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"service_name"`
+	// SampleRatio fracción de ejecuciones trazadas entre 0 y 1 (0 = todas)
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
 // Load carga la configuración desde archivos .env y YAML
 func Load(configPath string) (*Config, error) {
 	// Cargar variables de entorno
 	if err := godotenv.Load(); err != nil {
 		// No es un error crítico si no existe el archivo .env
-		fmt.Printf("Warning: .env file not found: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: .env file not found: %v\n", err)
 	}
 
 	// Cargar configuración YAML
@@ -69,6 +351,11 @@ func Load(configPath string) (*Config, error) {
 	// Cargar variables de entorno
 	config.TelegramBotToken = getEnv("TELEGRAM_BOT_TOKEN", "")
 	config.TelegramChatID = getEnv("TELEGRAM_CHAT_ID", "")
+	config.NtfyToken = getEnv("NTFY_TOKEN", "")
+	config.GotifyToken = getEnv("GOTIFY_TOKEN", "")
+	config.MatrixToken = getEnv("MATRIX_ACCESS_TOKEN", "")
+	config.MQTTPassword = getEnv("MQTT_PASSWORD", "")
+	config.APIToken = getEnv("API_TOKEN", "")
 	config.DockerHost = getEnv("DOCKER_HOST", "unix:///var/run/docker.sock")
 	config.LogLevel = getEnv("LOG_LEVEL", "info")
 
@@ -93,9 +380,129 @@ func (c *Config) validate() error {
 		if c.TelegramBotToken == "" {
 			return fmt.Errorf("TELEGRAM_BOT_TOKEN is required when telegram notifications are enabled")
 		}
-		if c.TelegramChatID == "" {
-			return fmt.Errorf("TELEGRAM_CHAT_ID is required when telegram notifications are enabled")
+		if c.TelegramChatID == "" && len(c.Notifications.Telegram.Chats) == 0 {
+			return fmt.Errorf("TELEGRAM_CHAT_ID or notifications.telegram.chats is required when telegram notifications are enabled")
+		}
+		for i, chat := range c.Notifications.Telegram.Chats {
+			if chat.ChatID == "" {
+				return fmt.Errorf("notifications.telegram.chats[%d].chat_id is required", i)
+			}
+		}
+	}
+
+	if bot := c.Notifications.Telegram.Bot; bot.Enabled {
+		if c.TelegramBotToken == "" {
+			return fmt.Errorf("TELEGRAM_BOT_TOKEN is required when the telegram bot is enabled")
+		}
+		if len(bot.AllowedChatIDs) == 0 && len(bot.AllowedUserIDs) == 0 {
+			return fmt.Errorf("notifications.telegram.bot requires allowed_chat_ids or allowed_user_ids")
+		}
+	}
+
+	if c.Notifications.Ntfy.Enabled && c.Notifications.Ntfy.URL == "" {
+		return fmt.Errorf("notifications.ntfy.url is required when ntfy notifications are enabled")
+	}
+
+	if c.Notifications.Gotify.Enabled {
+		if c.Notifications.Gotify.URL == "" {
+			return fmt.Errorf("notifications.gotify.url is required when gotify notifications are enabled")
+		}
+		if c.GotifyToken == "" {
+			return fmt.Errorf("GOTIFY_TOKEN is required when gotify notifications are enabled")
+		}
+	}
+
+	if c.Notifications.Matrix.Enabled {
+		if c.Notifications.Matrix.HomeserverURL == "" || c.Notifications.Matrix.RoomID == "" {
+			return fmt.Errorf("notifications.matrix.homeserver_url and room_id are required when matrix notifications are enabled")
+		}
+		if c.MatrixToken == "" {
+			return fmt.Errorf("MATRIX_ACCESS_TOKEN is required when matrix notifications are enabled")
+		}
+	}
+
+	if mqtt := c.Notifications.MQTT; mqtt.Enabled {
+		if mqtt.Broker == "" {
+			return fmt.Errorf("notifications.mqtt.broker is required when mqtt notifications are enabled")
+		}
+		if mqtt.QoS < 0 || mqtt.QoS > 1 {
+			return fmt.Errorf("notifications.mqtt.qos must be 0 or 1")
+		}
+	}
+
+	if syslog := c.Notifications.Syslog; syslog.Enabled {
+		switch syslog.Network {
+		case "", "unix", "udp", "tcp":
+		default:
+			return fmt.Errorf("notifications.syslog.network must be udp, tcp, unix or empty")
 		}
+		if syslog.Network != "" && syslog.Address == "" {
+			return fmt.Errorf("notifications.syslog.address is required when network is set")
+		}
+	}
+
+	for i, execCfg := range c.Notifications.Exec {
+		if len(execCfg.Command) == 0 {
+			return fmt.Errorf("notifications.exec[%d].command is required", i)
+		}
+	}
+
+	for i, route := range c.Notifications.Routes {
+		if len(route.Notifiers) == 0 {
+			return fmt.Errorf("notifications.routes[%d] must list at least one notifier", i)
+		}
+		for _, status := range route.Match.Status {
+			switch status {
+			case "available", "failed", "up_to_date":
+			default:
+				return fmt.Errorf("notifications.routes[%d]: unknown status %q", i, status)
+			}
+		}
+		for _, bump := range route.Match.Bump {
+			switch bump {
+			case "major", "minor", "patch", "unknown":
+			default:
+				return fmt.Errorf("notifications.routes[%d]: unknown bump level %q", i, bump)
+			}
+		}
+	}
+
+	if c.Notifications.Outbox.Enabled && c.Notifications.Outbox.Dir == "" {
+		return fmt.Errorf("notifications.outbox.dir is required when the outbox is enabled")
+	}
+
+	if quiet := c.Notifications.Throttle.QuietHours; (quiet.Start == "") != (quiet.End == "") {
+		return fmt.Errorf("notifications.throttle.quiet_hours requires both start and end")
+	}
+	for name, limit := range c.Notifications.Throttle.RateLimits {
+		if limit.Max <= 0 || limit.Per <= 0 {
+			return fmt.Errorf("notifications.throttle.rate_limits.%s requires a positive max and per", name)
+		}
+	}
+	if c.Notifications.Throttle.CollapseFailures < 0 {
+		return fmt.Errorf("notifications.throttle.collapse_failures must not be negative")
+	}
+
+	if c.Notifications.ReminderDays < 0 {
+		return fmt.Errorf("notifications.reminder_days must not be negative")
+	}
+
+	if c.Reports.MaxBackups < 0 {
+		return fmt.Errorf("reports.max_backups must not be negative")
+	}
+	if dir := c.Reports.SARIFBaseDir; dir != "" && !filepath.IsAbs(dir) {
+		return fmt.Errorf("reports.sarif_base_dir must be an absolute path")
+	}
+
+	if path := c.HTTP.Metrics.Path; path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("http.metrics.path must start with /")
+	}
+	if c.HTTP.API.Enabled && c.APIToken == "" {
+		return fmt.Errorf("API_TOKEN is required when the http api is enabled")
+	}
+
+	if ratio := c.Tracing.SampleRatio; ratio < 0 || ratio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
 	}
 
 	if err := c.Checker.ValidateCronSchedule(); err != nil {
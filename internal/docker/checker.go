@@ -2,8 +2,12 @@ package docker
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 // CheckStrategy define la interfaz para diferentes estrategias de verificación
@@ -43,13 +47,17 @@ func (c *Checker) RegisterStrategy(strategy CheckStrategy) {
 }
 
 // CheckAll verifica todos los contenedores
-func (c *Checker) CheckAll(ctx context.Context) (*model.CheckReport, error) {
+func (c *Checker) CheckAll(ctx context.Context) (report *model.CheckReport, err error) {
+	ctx, span := tracing.Start(ctx, "Checker.CheckAll")
+	defer func() { tracing.End(span, err) }()
+
 	containers, err := c.client.ListContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.Int("containers.total", len(containers)))
 
-	report := &model.CheckReport{
+	report = &model.CheckReport{
 		Total:     len(containers),
 		Available: make([]model.UpdateInfo, 0),
 		Failed:    make([]model.UpdateInfo, 0),
@@ -83,7 +91,7 @@ func (c *Checker) CheckAll(ctx context.Context) (*model.CheckReport, error) {
 func (c *Checker) checkContainer(ctx context.Context, container model.Container) (*model.UpdateInfo, error) {
 	for _, strategy := range c.strategies {
 		if strategy.CanHandle(container) {
-			return strategy.Check(ctx, container)
+			return checkWithSpan(ctx, strategy, container)
 		}
 	}
 
@@ -95,6 +103,25 @@ func (c *Checker) checkContainer(ctx context.Context, container model.Container)
 	}, nil
 }
 
+// checkWithSpan ejecuta CheckStrategy.Check dentro de un span con el
+// contenedor y el resultado
+func checkWithSpan(ctx context.Context, strategy CheckStrategy, container model.Container) (*model.UpdateInfo, error) {
+	ctx, span := tracing.Start(ctx, "CheckStrategy.Check",
+		semconv.ContainerName(container.Name),
+		semconv.ContainerImageName(container.ImageName),
+		attribute.String("check.strategy", fmt.Sprintf("%T", strategy)),
+	)
+
+	updateInfo, err := strategy.Check(ctx, container)
+	spanErr := err
+	if err == nil && updateInfo != nil {
+		span.SetAttributes(attribute.String("check.status", string(updateInfo.Status())))
+		spanErr = updateInfo.Error
+	}
+	tracing.End(span, spanErr)
+	return updateInfo, err
+}
+
 // Close cierra el cliente
 func (c *Checker) Close() error {
 	return c.client.Close()
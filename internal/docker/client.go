@@ -42,6 +42,7 @@ func (dc *DockerClient) ListContainers(ctx context.Context) ([]model.Container,
 			ImageName: c.Image,
 			ImageID:   c.ImageID,
 			Status:    c.Status,
+			Labels:    c.Labels,
 		}
 
 		result = append(result, container)
@@ -3,15 +3,27 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+	"github.com/pablopin/docker-image-checker/internal/metrics"
 	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
+// dockerHubAPI host de la API de tags de Docker Hub
+const dockerHubAPI = "registry.hub.docker.com"
+
 // RegistryStrategy implementa verificación contra registros remotos
 type RegistryStrategy struct {
 	dockerClient *DockerClient
@@ -66,7 +78,7 @@ func (rs *RegistryStrategy) Check(ctx context.Context, container model.Container
 	}
 
 	// Verificar contra el registro remoto
-	remoteInfo, _, err := rs.dockerClient.DistributionInspect(ctx, container.ImageName)
+	remoteInfo, err := rs.distributionInspect(ctx, container)
 	if err != nil {
 		// Si no se puede obtener info remota, asumimos que está actualizada
 		// (evita falsos positivos para imágenes privadas o locales)
@@ -81,7 +93,7 @@ func (rs *RegistryStrategy) Check(ctx context.Context, container model.Container
 	if localDigest != remoteDigest {
 		updateInfo.IsUpToDate = false
 		// Intentar obtener la versión más reciente desde Docker Hub
-		latestVersion, err := rs.getLatestVersionFromDockerHub(container.ImageName)
+		latestVersion, err := rs.getLatestVersionFromDockerHub(ctx, container.ImageName)
 		if err != nil {
 			// Si falla, usar el tag extraído de la imagen
 			updateInfo.LatestVersion = rs.extractTag(container.ImageName)
@@ -93,6 +105,24 @@ func (rs *RegistryStrategy) Check(ctx context.Context, container model.Container
 	return updateInfo, nil
 }
 
+// distributionInspect consulta el digest remoto a través del daemon, que es
+// quien hace las peticiones al registro; se mide como una petición al registro
+func (rs *RegistryStrategy) distributionInspect(ctx context.Context, container model.Container) (registry.DistributionInspect, error) {
+	ctx, span := tracing.Start(ctx, "registry.DistributionInspect",
+		attribute.String("registry", container.Registry()),
+		semconv.ContainerImageName(container.ImageName),
+	)
+
+	start := time.Now()
+	remoteInfo, _, err := rs.dockerClient.DistributionInspect(ctx, container.ImageName)
+	code := statusCode(err)
+	metrics.ObserveRegistryRequest(container.Registry(), code, time.Since(start))
+
+	span.SetAttributes(attribute.String("registry.status_code", code))
+	tracing.End(span, err)
+	return remoteInfo, err
+}
+
 // extractTag extrae el tag de una imagen completa
 func (rs *RegistryStrategy) extractTag(image string) string {
 	parts := strings.Split(image, ":")
@@ -114,7 +144,7 @@ type DockerHubTag struct {
 }
 
 // getLatestVersionFromDockerHub obtiene la última versión desde Docker Hub
-func (rs *RegistryStrategy) getLatestVersionFromDockerHub(imageName string) (string, error) {
+func (rs *RegistryStrategy) getLatestVersionFromDockerHub(ctx context.Context, imageName string) (string, error) {
 	// Extraer repositorio de la imagen (ej: "nginx:latest" -> "nginx")
 	repo := rs.extractRepository(imageName)
 	if repo == "" {
@@ -122,14 +152,22 @@ func (rs *RegistryStrategy) getLatestVersionFromDockerHub(imageName string) (str
 	}
 
 	// Construir URL de la API de Docker Hub
-	url := fmt.Sprintf("https://registry.hub.docker.com/v2/repositories/%s/tags/?page_size=100", repo)
+	url := fmt.Sprintf("https://%s/v2/repositories/%s/tags/?page_size=100", dockerHubAPI, repo)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
+		return "", fmt.Errorf("failed to create Docker Hub request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: &tracing.Transport{}}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		metrics.ObserveRegistryRequest(dockerHubAPI, statusCode(err), time.Since(start))
 		return "", fmt.Errorf("failed to fetch tags from Docker Hub: %w", err)
 	}
 	defer resp.Body.Close()
+	metrics.ObserveRegistryRequest(dockerHubAPI, strconv.Itoa(resp.StatusCode), time.Since(start))
 
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("Docker Hub API returned status code: %d", resp.StatusCode)
@@ -173,3 +211,31 @@ func (rs *RegistryStrategy) extractRepository(imageName string) string {
 
 	return repo
 }
+
+// statusCode código HTTP equivalente al resultado de una petición al
+// registro a través del daemon: los errores del daemon se clasifican con
+// errdefs y los de red se agrupan como timeout o error
+func statusCode(err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return "200"
+	case errdefs.IsUnauthorized(err):
+		return "401"
+	case errdefs.IsForbidden(err):
+		return "403"
+	case errdefs.IsNotFound(err):
+		return "404"
+	case errdefs.IsInvalidParameter(err):
+		return "400"
+	case errdefs.IsUnavailable(err):
+		return "503"
+	case errdefs.IsSystem(err):
+		return "500"
+	case errors.Is(err, context.DeadlineExceeded), errdefs.IsDeadline(err),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "error"
+	}
+}
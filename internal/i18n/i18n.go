@@ -0,0 +1,107 @@
+// Package i18n contiene el catálogo de mensajes de la consola, el bot y las
+// plantillas por defecto, con el idioma elegido por configuración o por LANG.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Idiomas soportados
+const (
+	English = "en"
+	Spanish = "es"
+)
+
+// catalogs mensajes por idioma; el inglés contiene todas las claves
+var catalogs = map[string]map[string]string{
+	English: messagesEN,
+	Spanish: messagesES,
+}
+
+var (
+	mu       sync.RWMutex
+	language = Resolve("")
+)
+
+// Supported indica si hay catálogo para un idioma
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Resolve elige el idioma: el configurado o, si está vacío, el de las
+// variables LC_ALL, LC_MESSAGES o LANG (ej: es_ES.UTF-8). Si no hay
+// catálogo para él se usa el inglés.
+func Resolve(configured string) string {
+	candidates := []string{configured}
+	if configured == "" {
+		candidates = []string{os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		lang := normalize(candidate)
+		if Supported(lang) {
+			return lang
+		}
+		return English
+	}
+	return English
+}
+
+// normalize reduce un locale (es_ES.UTF-8, es-ES) a su código de idioma
+func normalize(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang, _, _ = strings.Cut(lang, "-")
+	return strings.ToLower(lang)
+}
+
+// SetLanguage cambia el idioma de los mensajes
+func SetLanguage(lang string) error {
+	if !Supported(lang) {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	language = lang
+	return nil
+}
+
+// Language devuelve el idioma actual
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T traduce un mensaje y le aplica los argumentos con fmt.Sprintf. Si falta
+// en el idioma actual se usa el inglés y, si tampoco existe, la propia clave.
+func T(key string, args ...interface{}) string {
+	message, ok := catalogs[Language()][key]
+	if !ok {
+		message, ok = messagesEN[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// TN traduce un mensaje con forma singular (clave.one) y plural
+// (clave.other) según n, que se pasa como primer argumento
+func TN(key string, n int, args ...interface{}) string {
+	form := ".other"
+	if n == 1 {
+		form = ".one"
+	}
+	return T(key+form, append([]interface{}{n}, args...)...)
+}
@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"testing"
+)
+
+// useLanguage fija el idioma durante un test
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	previous := Language()
+	if err := SetLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetLanguage(previous) })
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		env        map[string]string
+		want       string
+	}{
+		{name: "configured", configured: "es", env: map[string]string{"LANG": "en_US.UTF-8"}, want: Spanish},
+		{name: "configured locale", configured: "es-MX", want: Spanish},
+		{name: "unsupported configured", configured: "fr", env: map[string]string{"LANG": "es_ES.UTF-8"}, want: English},
+		{name: "LANG", env: map[string]string{"LANG": "es_ES.UTF-8"}, want: Spanish},
+		{name: "LC_MESSAGES before LANG", env: map[string]string{"LC_MESSAGES": "es_AR", "LANG": "en_GB.UTF-8"}, want: Spanish},
+		{name: "LC_ALL before the rest", env: map[string]string{"LC_ALL": "en_US", "LC_MESSAGES": "es_ES", "LANG": "es_ES"}, want: English},
+		{name: "upper case", env: map[string]string{"LANG": "ES_ES"}, want: Spanish},
+		// El primer locale definido decide aunque no haya catálogo para él
+		{name: "unsupported LANG", env: map[string]string{"LC_ALL": "de_DE.UTF-8", "LANG": "es_ES"}, want: English},
+		{name: "POSIX locale", env: map[string]string{"LANG": "C"}, want: English},
+		{name: "nothing set", want: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := Resolve(tt.configured); got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	useLanguage(t, Spanish)
+	if err := SetLanguage("fr"); err == nil {
+		t.Error("SetLanguage(fr) should fail")
+	}
+	if got := Language(); got != Spanish {
+		t.Errorf("Language() = %q after a failed SetLanguage, want %q", got, Spanish)
+	}
+}
+
+func TestT(t *testing.T) {
+	messagesEN["test.only_english"] = "only in %s"
+	t.Cleanup(func() { delete(messagesEN, "test.only_english") })
+
+	useLanguage(t, Spanish)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "translated", got: T("check.done"), want: messagesES["check.done"]},
+		{name: "arguments", got: T("bot.invalid_duration", "soon"), want: "❌ Duración no válida: soon"},
+		{name: "english fallback", got: T("test.only_english", "en"), want: "only in en"},
+		{name: "unknown key", got: T("test.missing"), want: "test.missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTN(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{lang: English, n: 0, want: "📦 0 updates available"},
+		{lang: English, n: 1, want: "📦 1 update available"},
+		{lang: English, n: 2, want: "📦 2 updates available"},
+		{lang: Spanish, n: 1, want: "📦 1 actualización disponible"},
+		{lang: Spanish, n: 3, want: "📦 3 actualizaciones disponibles"},
+	}
+	for _, tt := range tests {
+		useLanguage(t, tt.lang)
+		if got := TN("tmpl.updates", tt.n); got != tt.want {
+			t.Errorf("TN(tmpl.updates, %d) in %s = %q, want %q", tt.n, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsHaveTheSameKeys(t *testing.T) {
+	for key := range messagesEN {
+		if _, ok := messagesES[key]; !ok {
+			t.Errorf("key %q missing from the Spanish catalog", key)
+		}
+	}
+	for key := range messagesES {
+		if _, ok := messagesEN[key]; !ok {
+			t.Errorf("key %q missing from the English catalog", key)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package i18n
+
+// messagesEN catálogo en inglés
+var messagesEN = map[string]string{
+	// Consola
+	"check.start":           "--- Starting Docker image check ---",
+	"check.done":            "--- Check completed ---",
+	"notify.sending":        "📢 Sending notifications (updates: %d, failures: %d)...",
+	"notify.sent":           "✅ Notifications sent successfully",
+	"notify.no_changes":     "ℹ️  No changes since the last notification, nothing to send",
+	"notify.nothing":        "ℹ️  No updates or failures, nothing to send",
+	"notify.pending_retry":  "⏳ Some notifications are pending a retry: %v",
+	"daemon.start":          "--- Starting daemon mode (schedule: %s) ---",
+	"daemon.stop":           "--- Stopping daemon ---",
+	"daemon.bot":            "🤖 Telegram bot listening for commands",
+	"daemon.http":           "🌐 HTTP server listening on %s",
+	"report.summary":        "📊 Summary:",
+	"report.host":           "Host",
+	"report.available":      "Containers with updates available",
+	"report.checked":        "Containers checked",
+	"report.failed":         "Failed",
+	"report.updates":        "📦 Updates available:",
+	"report.current":        "Current version",
+	"report.latest":         "New version",
+	"report.failures":       "🚫 Failures:",
+	"report.up_to_date":     "✅ Up to date (%d):",
+	"throttle.quiet":        "🌙 Quiet hours: notification held until %s",
+	"throttle.collapsing":   "🧯 Collapsing failure notifications until %s",
+	"throttle.rate_limited": "⏱️ Rate limit reached for %s, notification deferred until %s",
+	"throttle.flush":        "📬 Delivering held notifications",
+	"telegram.preparing":    "🔔 Preparing Telegram notification...",
+	"telegram.generated":    "📝 Message generated (first 100 chars): %.100s...",
+	"telegram.send_error":   "❌ Error sending Telegram message to %s: %v",
+	"telegram.sent":         "✅ Telegram message sent successfully",
+	"telegram.summary":      "🛟 %d updates · ❌ %d failed · ✅ %d checked",
+	"bot.checking":          "⏳ Checking images...",
+	"bot.check_error":       "❌ Check failed: %v",
+	"bot.no_report":         "ℹ️ There is no report yet, use /check",
+	"bot.ignore_usage":      "Usage: /ignore <container> [duration, e.g. 12h or 7d]",
+	"bot.invalid_duration":  "❌ Invalid duration: %s",
+	"bot.ignore_error":      "❌ Could not ignore %s: %v",
+	"bot.ignored":           "🔕 %s ignored until %s",
+	"bot.unknown":           "Unknown command, use /help",
+	"bot.help":              "🐳 Docker Image Checker\n/check - Run a check now\n/status - Show the last report\n/ignore <container> [duration] - Snooze a container (default 7d)\n/help - Show this help",
+
+	// Plantillas por defecto
+	"tmpl.host":            "🖥️ Host: %s",
+	"tmpl.available_count": "🛟 Updates available: %d",
+	"tmpl.checked":         "✅ Containers checked: %d",
+	"tmpl.failed_count":    "❌ Failed: %d",
+	"tmpl.available":       "📦 Updates available:",
+	"tmpl.failed":          "🚫 Failures:",
+	"tmpl.resolved":        "✔️ Resolved since the last notification:",
+	"tmpl.applied":         "⬆️ Updates applied:",
+	"tmpl.recovered":       "💚 Checks working again:",
+	"tmpl.removed":         "🗑️ No longer checked:",
+	"tmpl.no_report":       "⚠️ The update report could not be generated.",
+	"tmpl.updates.one":     "📦 %d update available",
+	"tmpl.updates.other":   "📦 %d updates available",
+	"tmpl.failures.one":    "🚨 %d check failed",
+	"tmpl.failures.other":  "🚨 %d checks failed",
+	"tmpl.recovery.one":    "✔️ %d container resolved",
+	"tmpl.recovery.other":  "✔️ %d containers resolved",
+	"tmpl.recovery.none":   "✔️ Nothing pending",
+
+	// Páginas de reporte (HTML y Markdown)
+	"page.title":             "Image update report: %s",
+	"page.checked_at":        "Checked at",
+	"page.total":             "Containers checked",
+	"page.status":            "Status",
+	"page.container":         "Container",
+	"page.image":             "Image",
+	"page.registry":          "Registry",
+	"page.compose_project":   "Compose project",
+	"page.current":           "Current version",
+	"page.latest":            "Latest version",
+	"page.bump":              "Bump",
+	"page.empty":             "No containers were checked.",
+	"page.status.available":  "Update available",
+	"page.status.failed":     "Check failed",
+	"page.status.up_to_date": "Up to date",
+}
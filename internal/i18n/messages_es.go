@@ -0,0 +1,82 @@
+package i18n
+
+// messagesES catálogo en español
+var messagesES = map[string]string{
+	// Consola
+	"check.start":           "--- Iniciando verificación de imágenes Docker ---",
+	"check.done":            "--- Verificación completada ---",
+	"notify.sending":        "📢 Enviando notificaciones (Actualizaciones: %d, Errores: %d)...",
+	"notify.sent":           "✅ Notificaciones enviadas exitosamente",
+	"notify.no_changes":     "ℹ️  Sin cambios desde la última notificación, no se envían notificaciones",
+	"notify.nothing":        "ℹ️  No hay actualizaciones ni errores, no se envían notificaciones",
+	"notify.pending_retry":  "⏳ Algunas notificaciones quedan pendientes de reintento: %v",
+	"daemon.start":          "--- Iniciando modo daemon (schedule: %s) ---",
+	"daemon.stop":           "--- Deteniendo daemon ---",
+	"daemon.bot":            "🤖 Bot de Telegram escuchando comandos",
+	"daemon.http":           "🌐 Servidor HTTP escuchando en %s",
+	"report.summary":        "📊 Resumen:",
+	"report.host":           "Host",
+	"report.available":      "Contenedores con actualizaciones disponibles",
+	"report.checked":        "Contenedores verificados",
+	"report.failed":         "Fallidos",
+	"report.updates":        "📦 Actualizaciones disponibles:",
+	"report.current":        "Versión actual",
+	"report.latest":         "Nueva versión",
+	"report.failures":       "🚫 Fallos en:",
+	"report.up_to_date":     "✅ Actualizados (%d):",
+	"throttle.quiet":        "🌙 Horas de silencio: notificación retenida hasta las %s",
+	"throttle.collapsing":   "🧯 Agrupando notificaciones de fallos hasta las %s",
+	"throttle.rate_limited": "⏱️ Límite de envíos alcanzado para %s, notificación aplazada hasta las %s",
+	"throttle.flush":        "📬 Enviando notificaciones retenidas",
+	"telegram.preparing":    "🔔 Preparando notificación de Telegram...",
+	"telegram.generated":    "📝 Mensaje generado (primeros 100 chars): %.100s...",
+	"telegram.send_error":   "❌ Error enviando mensaje de Telegram a %s: %v",
+	"telegram.sent":         "✅ Mensaje de Telegram enviado exitosamente",
+	"telegram.summary":      "🛟 %d actualizaciones · ❌ %d fallos · ✅ %d verificados",
+	"bot.checking":          "⏳ Verificando imágenes...",
+	"bot.check_error":       "❌ Error en la verificación: %v",
+	"bot.no_report":         "ℹ️ Todavía no hay ningún reporte, usa /check",
+	"bot.ignore_usage":      "Uso: /ignore <contenedor> [duración, ej: 12h o 7d]",
+	"bot.invalid_duration":  "❌ Duración no válida: %s",
+	"bot.ignore_error":      "❌ No se pudo ignorar %s: %v",
+	"bot.ignored":           "🔕 %s ignorado hasta %s",
+	"bot.unknown":           "Comando desconocido, usa /help",
+	"bot.help":              "🐳 Docker Image Checker\n/check - Ejecutar una verificación ahora\n/status - Mostrar el último reporte\n/ignore <contenedor> [duración] - Silenciar un contenedor (por defecto 7d)\n/help - Mostrar esta ayuda",
+
+	// Plantillas por defecto
+	"tmpl.host":            "🖥️ Host: %s",
+	"tmpl.available_count": "🛟 Actualizaciones disponibles: %d",
+	"tmpl.checked":         "✅ Contenedores verificados: %d",
+	"tmpl.failed_count":    "❌ Fallidos: %d",
+	"tmpl.available":       "📦 Actualizaciones disponibles:",
+	"tmpl.failed":          "🚫 Fallos en:",
+	"tmpl.resolved":        "✔️ Resueltos desde la última notificación:",
+	"tmpl.applied":         "⬆️ Actualizaciones aplicadas:",
+	"tmpl.recovered":       "💚 Verificaciones recuperadas:",
+	"tmpl.removed":         "🗑️ Ya no se verifican:",
+	"tmpl.no_report":       "⚠️ No se pudo generar reporte de actualización.",
+	"tmpl.updates.one":     "📦 %d actualización disponible",
+	"tmpl.updates.other":   "📦 %d actualizaciones disponibles",
+	"tmpl.failures.one":    "🚨 %d verificación fallida",
+	"tmpl.failures.other":  "🚨 %d verificaciones fallidas",
+	"tmpl.recovery.one":    "✔️ %d contenedor resuelto",
+	"tmpl.recovery.other":  "✔️ %d contenedores resueltos",
+	"tmpl.recovery.none":   "✔️ Sin pendientes",
+
+	// Páginas de reporte (HTML y Markdown)
+	"page.title":             "Informe de actualizaciones de imágenes: %s",
+	"page.checked_at":        "Verificado el",
+	"page.total":             "Contenedores verificados",
+	"page.status":            "Estado",
+	"page.container":         "Contenedor",
+	"page.image":             "Imagen",
+	"page.registry":          "Registro",
+	"page.compose_project":   "Proyecto Compose",
+	"page.current":           "Versión actual",
+	"page.latest":            "Última versión",
+	"page.bump":              "Salto",
+	"page.empty":             "No se verificó ningún contenedor.",
+	"page.status.available":  "Actualización disponible",
+	"page.status.failed":     "Verificación fallida",
+	"page.status.up_to_date": "Actualizado",
+}
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// namespace prefijo de todas las métricas de la aplicación
+const namespace = "docker_image_checker"
+
+// Resultados de las etiquetas result
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var factory = promauto.With(Default)
+
+var (
+	// Runs verificaciones completas por resultado
+	Runs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_total",
+		Help:      "Check runs by result (success or failure).",
+	}, []string{"result"})
+	// LastRunTimestamp hora de la última verificación completada
+	LastRunTimestamp = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_run_timestamp_seconds",
+		Help:      "Unix time of the last completed check run.",
+	})
+	// CheckDuration duración de la verificación de todos los contenedores
+	CheckDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_duration_seconds",
+		Help:      "Duration of checking all containers in a run.",
+		Buckets:   []float64{1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	})
+
+	// RegistryRequests peticiones a registros por código de estado
+	RegistryRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registry_requests_total",
+		Help:      "Registry requests by registry and HTTP status code (or error).",
+	}, []string{"registry", "code"})
+	// RegistryRequestDuration latencia de las peticiones a registros
+	RegistryRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "registry_request_duration_seconds",
+		Help:      "Registry request latency.",
+		Buckets:   DefaultBuckets,
+	}, []string{"registry"})
+
+	// NotificationDeliveries entregas por notificador y resultado
+	NotificationDeliveries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_deliveries_total",
+		Help:      "Notification deliveries by notifier and result (success or failure).",
+	}, []string{"notifier", "result"})
+
+	// OutboxPending notificaciones guardadas en el outbox pendientes de entrega
+	OutboxPending = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_pending",
+		Help:      "Notifications waiting in the outbox for delivery or retry.",
+	})
+	// OutboxExpired notificaciones descartadas por caducar desde el arranque
+	OutboxExpired = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_expired",
+		Help:      "Notifications discarded from the outbox after max_age since the process started.",
+	})
+)
+
+// containers gauges por contenedor del último reporte
+var containers = &containerCollector{}
+
+func init() {
+	Default.MustRegister(containers)
+}
+
+var (
+	containerUpdateAvailableDesc = prometheus.NewDesc(namespace+"_container_update_available",
+		"1 if a newer image is available for the container, 0 otherwise.", []string{"container", "image"}, nil)
+	containerBumpLevelDesc = prometheus.NewDesc(namespace+"_container_bump_level",
+		"Available version bump: 0 none, 1 patch, 2 minor, 3 major, -1 unknown.", []string{"container", "image"}, nil)
+	containerCheckFailedDesc = prometheus.NewDesc(namespace+"_container_check_failed",
+		"1 if the last check of the container failed, 0 otherwise.", []string{"container", "image"}, nil)
+)
+
+// bumpLevels valor numérico de container_bump_level
+var bumpLevels = map[model.BumpLevel]float64{
+	model.BumpNone:    0,
+	model.BumpPatch:   1,
+	model.BumpMinor:   2,
+	model.BumpMajor:   3,
+	model.BumpUnknown: -1,
+}
+
+// containerCollector genera los gauges por contenedor en cada scrape a partir
+// del último reporte, que se sustituye entero: un scrape nunca ve un reporte
+// a medio actualizar y los contenedores que desaparecen dejan de exponerse
+type containerCollector struct {
+	mu      sync.RWMutex
+	updates []model.UpdateInfo
+}
+
+// Describe implementa prometheus.Collector
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- containerUpdateAvailableDesc
+	ch <- containerBumpLevelDesc
+	ch <- containerCheckFailedDesc
+}
+
+// Collect implementa prometheus.Collector
+func (c *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	updates := c.updates
+	c.mu.RUnlock()
+
+	for _, update := range updates {
+		name, image := update.Container.Name, update.Container.ImageName
+		status := update.Status()
+		bump := model.BumpNone
+		if status == model.StatusAvailable {
+			bump = update.Bump()
+		}
+
+		ch <- prometheus.MustNewConstMetric(containerUpdateAvailableDesc, prometheus.GaugeValue,
+			boolValue(status == model.StatusAvailable), name, image)
+		ch <- prometheus.MustNewConstMetric(containerBumpLevelDesc, prometheus.GaugeValue,
+			bumpLevels[bump], name, image)
+		ch <- prometheus.MustNewConstMetric(containerCheckFailedDesc, prometheus.GaugeValue,
+			boolValue(status == model.StatusFailed), name, image)
+	}
+}
+
+// RecordReport sustituye los gauges por contenedor por los del último reporte
+func RecordReport(report *model.CheckReport) {
+	var updates []model.UpdateInfo
+	for _, list := range [][]model.UpdateInfo{report.Available, report.Failed, report.UpToDate} {
+		updates = append(updates, list...)
+	}
+
+	containers.mu.Lock()
+	containers.updates = updates
+	containers.mu.Unlock()
+	LastRunTimestamp.Set(float64(report.Timestamp.Unix()))
+}
+
+// ObserveRegistryRequest registra una petición a un registro
+func ObserveRegistryRequest(registry, code string, duration time.Duration) {
+	registry = registryLabel(registry)
+	RegistryRequests.WithLabelValues(registry, code).Inc()
+	RegistryRequestDuration.WithLabelValues(registry).Observe(duration.Seconds())
+}
+
+// dockerHubHosts nombres con los que se accede a Docker Hub
+var dockerHubHosts = map[string]bool{
+	"docker.io":               true,
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+	"hub.docker.com":          true,
+}
+
+// registryLabel normaliza el registro de la etiqueta registry, de modo que
+// Docker Hub aparece siempre como "docker.io" sea cual sea el host usado
+func registryLabel(registry string) string {
+	registry = strings.ToLower(registry)
+	if dockerHubHosts[registry] {
+		return "docker.io"
+	}
+	return registry
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func testReport(containers ...model.UpdateInfo) *model.CheckReport {
+	report := &model.CheckReport{Timestamp: time.Unix(1700000000, 0)}
+	for _, update := range containers {
+		switch update.Status() {
+		case model.StatusAvailable:
+			report.Available = append(report.Available, update)
+		case model.StatusFailed:
+			report.Failed = append(report.Failed, update)
+		default:
+			report.UpToDate = append(report.UpToDate, update)
+		}
+	}
+	return report
+}
+
+var (
+	webMajor = model.UpdateInfo{
+		Container:      model.Container{Name: "web", ImageName: "nginx:1.25.3"},
+		CurrentVersion: "1.25.3",
+		LatestVersion:  "2.0.0",
+	}
+	dbFailed = model.UpdateInfo{
+		Container: model.Container{Name: "db", ImageName: "postgres:15.5"},
+		Error:     errors.New("401 Unauthorized"),
+	}
+	cacheUpToDate = model.UpdateInfo{
+		Container:      model.Container{Name: "cache", ImageName: "redis:7.2.4"},
+		CurrentVersion: "7.2.4",
+		LatestVersion:  "7.2.4",
+		IsUpToDate:     true,
+	}
+)
+
+func TestRecordReport(t *testing.T) {
+	RecordReport(testReport(webMajor, dbFailed))
+
+	expected := `
+# HELP docker_image_checker_container_bump_level Available version bump: 0 none, 1 patch, 2 minor, 3 major, -1 unknown.
+# TYPE docker_image_checker_container_bump_level gauge
+docker_image_checker_container_bump_level{container="db",image="postgres:15.5"} 0
+docker_image_checker_container_bump_level{container="web",image="nginx:1.25.3"} 3
+# HELP docker_image_checker_container_check_failed 1 if the last check of the container failed, 0 otherwise.
+# TYPE docker_image_checker_container_check_failed gauge
+docker_image_checker_container_check_failed{container="db",image="postgres:15.5"} 1
+docker_image_checker_container_check_failed{container="web",image="nginx:1.25.3"} 0
+# HELP docker_image_checker_container_update_available 1 if a newer image is available for the container, 0 otherwise.
+# TYPE docker_image_checker_container_update_available gauge
+docker_image_checker_container_update_available{container="db",image="postgres:15.5"} 0
+docker_image_checker_container_update_available{container="web",image="nginx:1.25.3"} 1
+`
+	if err := testutil.CollectAndCompare(containers, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.ToFloat64(LastRunTimestamp); got != 1700000000 {
+		t.Errorf("last run timestamp = %v", got)
+	}
+
+	// Los contenedores que ya no aparecen dejan de exponerse
+	RecordReport(testReport(cacheUpToDate))
+	expected = `
+# HELP docker_image_checker_container_update_available 1 if a newer image is available for the container, 0 otherwise.
+# TYPE docker_image_checker_container_update_available gauge
+docker_image_checker_container_update_available{container="cache",image="redis:7.2.4"} 0
+`
+	if err := testutil.CollectAndCompare(containers, strings.NewReader(expected),
+		"docker_image_checker_container_update_available"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordReportIsAtomic(t *testing.T) {
+	reports := []*model.CheckReport{
+		testReport(webMajor, dbFailed),
+		testReport(cacheUpToDate, dbFailed),
+	}
+	RecordReport(reports[0])
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				RecordReport(reports[i%2])
+			}
+		}
+	}()
+
+	// Cada scrape ve un reporte completo: 3 gauges por cada uno de los 2 contenedores
+	for i := 0; i < 200; i++ {
+		if n := testutil.CollectAndCount(containers); n != 6 {
+			t.Fatalf("scrape %d exposed %d container series, want 6", i, n)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestObserveRegistryRequestNormalizesRegistry(t *testing.T) {
+	tests := []struct {
+		registry string
+		want     string
+	}{
+		{"docker.io", "docker.io"},
+		{"registry.hub.docker.com", "docker.io"},
+		{"index.docker.io", "docker.io"},
+		{"registry-1.docker.io", "docker.io"},
+		{"GHCR.io", "ghcr.io"},
+		{"localhost:5000", "localhost:5000"},
+	}
+	for _, tt := range tests {
+		if got := registryLabel(tt.registry); got != tt.want {
+			t.Errorf("registryLabel(%q) = %q, want %q", tt.registry, got, tt.want)
+		}
+	}
+
+	before := testutil.ToFloat64(RegistryRequests.WithLabelValues("docker.io", "200"))
+	ObserveRegistryRequest("docker.io", "200", time.Millisecond)
+	ObserveRegistryRequest("registry.hub.docker.com", "200", time.Millisecond)
+	if got := testutil.ToFloat64(RegistryRequests.WithLabelValues("docker.io", "200")); got != before+2 {
+		t.Errorf("docker.io requests = %v, want %v", got, before+2)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Runs.WithLabelValues(ResultSuccess).Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	if rec.Code != 200 {
+		t.Fatalf("status %d", rec.Code)
+	}
+	for _, want := range []string{
+		`docker_image_checker_runs_total{result="success"}`,
+		"# TYPE docker_image_checker_check_duration_seconds histogram",
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// updateInfoJSON representación JSON de UpdateInfo con el error como texto
+type updateInfoJSON struct {
+	Container      Container `json:"container"`
+	CurrentVersion string    `json:"current_version"`
+	LatestVersion  string    `json:"latest_version"`
+	IsUpToDate     bool      `json:"is_up_to_date"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// MarshalJSON serializa el error como texto
+func (u UpdateInfo) MarshalJSON() ([]byte, error) {
+	out := updateInfoJSON{
+		Container:      u.Container,
+		CurrentVersion: u.CurrentVersion,
+		LatestVersion:  u.LatestVersion,
+		IsUpToDate:     u.IsUpToDate,
+	}
+	if u.Error != nil {
+		out.Error = u.Error.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON reconstruye el error a partir de su texto
+func (u *UpdateInfo) UnmarshalJSON(data []byte) error {
+	var in updateInfoJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*u = UpdateInfo{
+		Container:      in.Container,
+		CurrentVersion: in.CurrentVersion,
+		LatestVersion:  in.LatestVersion,
+		IsUpToDate:     in.IsUpToDate,
+	}
+	if in.Error != "" {
+		u.Error = errors.New(in.Error)
+	}
+	return nil
+}
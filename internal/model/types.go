@@ -1,48 +1,162 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Container representa un contenedor Docker
 type Container struct {
-	ID        string
-	Name      string
-	ImageName string
-	ImageID   string
-	Status    string
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	ImageName string            `json:"image"`
+	ImageID   string            `json:"image_id"`
+	Status    string            `json:"status"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// composeProjectLabel etiqueta que Docker Compose añade a sus contenedores
+const composeProjectLabel = "com.docker.compose.project"
+
+// ComposeProject devuelve el proyecto de Docker Compose del contenedor, si lo hay
+func (c Container) ComposeProject() string {
+	return c.Labels[composeProjectLabel]
+}
+
+// Registry devuelve el registro de la imagen del contenedor (docker.io si no se indica)
+func (c Container) Registry() string {
+	first, _, found := strings.Cut(c.ImageName, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
 }
 
 // ImageInfo contiene información sobre una imagen
 type ImageInfo struct {
-	Name          string
-	LocalDigest   string
-	RemoteDigest  string
-	CurrentTag    string
-	LatestTag     string
-	IsUpToDate    bool
-	Error         error
+	Name         string
+	LocalDigest  string
+	RemoteDigest string
+	CurrentTag   string
+	LatestTag    string
+	IsUpToDate   bool
+	Error        error
 }
 
-// UpdateInfo representa información de actualización para un contenedor
+// UpdateInfo representa información de actualización para un contenedor.
+// En JSON el error se serializa como texto (ver MarshalJSON).
 type UpdateInfo struct {
-	Container      Container
-	CurrentVersion string
-	LatestVersion  string
-	IsUpToDate     bool
-	Error          error
+	Container      Container `json:"container"`
+	CurrentVersion string    `json:"current_version"`
+	LatestVersion  string    `json:"latest_version"`
+	IsUpToDate     bool      `json:"is_up_to_date"`
+	Error          error     `json:"-"`
+}
+
+// UpdateStatus clasifica el resultado de la verificación de un contenedor
+type UpdateStatus string
+
+const (
+	StatusUpToDate  UpdateStatus = "up_to_date"
+	StatusAvailable UpdateStatus = "available"
+	StatusFailed    UpdateStatus = "failed"
+)
+
+// Status devuelve el estado de la verificación
+func (u UpdateInfo) Status() UpdateStatus {
+	switch {
+	case u.Error != nil:
+		return StatusFailed
+	case !u.IsUpToDate:
+		return StatusAvailable
+	default:
+		return StatusUpToDate
+	}
 }
 
 // CheckReport representa el reporte completo de verificación
 type CheckReport struct {
-	Hostname  string
-	Timestamp time.Time
-	Total     int
-	Available []UpdateInfo
-	Failed    []UpdateInfo
-	UpToDate  []UpdateInfo
+	Hostname  string       `json:"hostname"`
+	Timestamp time.Time    `json:"timestamp"`
+	Total     int          `json:"total"`
+	Available []UpdateInfo `json:"available"`
+	Failed    []UpdateInfo `json:"failed"`
+	UpToDate  []UpdateInfo `json:"up_to_date"`
 }
 
+// ChangeSet resume las diferencias respecto a la última notificación enviada
+type ChangeSet struct {
+	NewUpdates  []UpdateInfo `json:"new_updates"`
+	NewFailures []UpdateInfo `json:"new_failures"`
+	// Resolved todo lo que estaba pendiente y ya no lo está; Recovered,
+	// Applied y Removed lo desglosan según el motivo
+	Resolved  []UpdateInfo `json:"resolved"`
+	Reminders []UpdateInfo `json:"reminders"`
+
+	// Recovered fallaban y ahora se verifican correctamente
+	Recovered []UpdateInfo `json:"recovered,omitempty"`
+	// Applied tenían una actualización que ya no está disponible porque el
+	// contenedor se ha recreado con la imagen nueva
+	Applied []UpdateInfo `json:"applied,omitempty"`
+	// Removed ya no aparecen en la verificación (contenedor eliminado o excluido)
+	Removed []UpdateInfo `json:"removed,omitempty"`
+}
+
+// IsEmpty indica si no hay nada nuevo que notificar
+func (c *ChangeSet) IsEmpty() bool {
+	return len(c.NewUpdates) == 0 && len(c.NewFailures) == 0 &&
+		len(c.Resolved) == 0 && len(c.Reminders) == 0
+}
+
+// EventType clasifica una notificación para elegir su plantilla
+type EventType string
+
+const (
+	// EventUpdates hay actualizaciones disponibles
+	EventUpdates EventType = "updates"
+	// EventFailures alguna verificación ha fallado
+	EventFailures EventType = "failures"
+	// EventRecovery solo hay elementos resueltos desde la última notificación
+	EventRecovery EventType = "recovery"
+)
+
+// EventTypes lista todos los tipos de evento
+var EventTypes = []EventType{EventUpdates, EventFailures, EventRecovery}
+
 // NotificationData representa los datos para las notificaciones
 type NotificationData struct {
-	Report   *CheckReport
-	Hostname string
+	Report   *CheckReport `json:"report"`
+	Hostname string       `json:"hostname"`
+	// Changes es nil cuando no hay seguimiento de estado entre ejecuciones
+	Changes *ChangeSet `json:"changes,omitempty"`
+}
+
+// Event devuelve el tipo de evento de la notificación. Con seguimiento de
+// estado se decide por lo que ha cambiado (fallos nuevos o recordados,
+// actualizaciones, o solo resueltos); sin él, por si el reporte tiene fallos.
+func (d *NotificationData) Event() EventType {
+	if d.Changes != nil {
+		switch {
+		case len(d.Changes.NewFailures) > 0 || hasFailures(d.Changes.Reminders):
+			return EventFailures
+		case len(d.Changes.NewUpdates) > 0 || len(d.Changes.Reminders) > 0:
+			return EventUpdates
+		case len(d.Changes.Resolved) > 0:
+			return EventRecovery
+		}
+	}
+	if d.Report != nil && len(d.Report.Failed) > 0 {
+		return EventFailures
+	}
+	return EventUpdates
+}
+
+// hasFailures indica si alguna de las verificaciones ha fallado
+func hasFailures(updates []UpdateInfo) bool {
+	for _, update := range updates {
+		if update.Status() == StatusFailed {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BumpLevel indica la magnitud del salto entre dos versiones
+type BumpLevel string
+
+const (
+	BumpNone    BumpLevel = "none"
+	BumpPatch   BumpLevel = "patch"
+	BumpMinor   BumpLevel = "minor"
+	BumpMajor   BumpLevel = "major"
+	BumpUnknown BumpLevel = "unknown"
+)
+
+// Bump calcula el nivel de salto entre la versión actual y la nueva
+func (u UpdateInfo) Bump() BumpLevel {
+	return CompareVersions(u.CurrentVersion, u.LatestVersion)
+}
+
+// HasMajorUpdates indica si alguna actualización disponible es de versión mayor
+func (r *CheckReport) HasMajorUpdates() bool {
+	for _, update := range r.Available {
+		if update.Bump() == BumpMajor {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareVersions compara dos tags con formato semver (ej: "v1.2.3-alpine")
+// y devuelve BumpUnknown si alguno de ellos no es numérico (ej: "latest")
+func CompareVersions(current, latest string) BumpLevel {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return BumpUnknown
+	}
+	lat, ok := parseVersion(latest)
+	if !ok {
+		return BumpUnknown
+	}
+
+	levels := [3]BumpLevel{BumpMajor, BumpMinor, BumpPatch}
+	for i := range cur {
+		switch {
+		case lat[i] > cur[i]:
+			return levels[i]
+		case lat[i] < cur[i]:
+			// Una "nueva" versión menor que la actual no es comparable
+			return BumpUnknown
+		}
+	}
+
+	return BumpNone
+}
+
+// parseVersion extrae major, minor y patch de un tag ignorando prefijos y sufijos
+func parseVersion(tag string) ([3]int, bool) {
+	var version [3]int
+
+	tag = strings.TrimPrefix(strings.ToLower(tag), "v")
+	if i := strings.IndexAny(tag, "-+_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" {
+		return version, false
+	}
+
+	parts := strings.Split(tag, ".")
+	for i, part := range parts {
+		if i >= len(version) {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, false
+		}
+		version[i] = n
+	}
+
+	return version, true
+}
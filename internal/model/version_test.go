@@ -0,0 +1,52 @@
+package model
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    BumpLevel
+	}{
+		{name: "major", current: "1.25.3", latest: "2.0.0", want: BumpMajor},
+		{name: "minor", current: "1.25.3", latest: "1.26.0", want: BumpMinor},
+		{name: "patch", current: "1.25.3", latest: "1.25.4", want: BumpPatch},
+		{name: "same version", current: "1.25.3", latest: "1.25.3", want: BumpNone},
+		{name: "v prefix", current: "v1.2.3", latest: "V1.3.0", want: BumpMinor},
+		{name: "suffix is ignored", current: "1.25.3-alpine", latest: "1.25.4-alpine", want: BumpPatch},
+		{name: "build metadata", current: "1.2.3+build1", latest: "1.2.3+build2", want: BumpNone},
+		{name: "major only", current: "15", latest: "16", want: BumpMajor},
+		{name: "missing parts are zero", current: "1.2", latest: "1.2.1", want: BumpPatch},
+		{name: "extra parts are ignored", current: "1.2.3.4", latest: "1.2.3.9", want: BumpNone},
+		{name: "numeric comparison", current: "1.9.0", latest: "1.10.0", want: BumpMinor},
+		{name: "downgrade", current: "2.0.0", latest: "1.9.9", want: BumpUnknown},
+		{name: "latest tag", current: "latest", latest: "1.25.4", want: BumpUnknown},
+		{name: "named tag", current: "1.25.3", latest: "stable", want: BumpUnknown},
+		{name: "empty", current: "", latest: "1.0.0", want: BumpUnknown},
+		{name: "date tag", current: "2024.01", latest: "2024.02", want: BumpMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareVersions(tt.current, tt.latest); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %s, want %s", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasMajorUpdates(t *testing.T) {
+	report := &CheckReport{Available: []UpdateInfo{
+		{CurrentVersion: "1.25.3", LatestVersion: "1.25.4"},
+		{CurrentVersion: "latest", LatestVersion: "latest"},
+	}}
+	if report.HasMajorUpdates() {
+		t.Error("patch and unknown bumps are not major")
+	}
+
+	report.Available = append(report.Available, UpdateInfo{CurrentVersion: "v1.4.0", LatestVersion: "v2.0.0"})
+	if !report.HasMajorUpdates() {
+		t.Error("expected a major update")
+	}
+}
@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// discordMaxContent es el límite de caracteres del campo content de Discord
+const discordMaxContent = 2000
+
+// DiscordOptions configuración del notificador de Discord
+type DiscordOptions struct {
+	// WebhookURL URL del webhook (https://discord.com/api/webhooks/{id}/{token})
+	WebhookURL   string
+	Username     string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// DiscordNotifier implementa Observer para webhooks de Discord
+type DiscordNotifier struct {
+	options   DiscordOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// NewDiscordNotifier crea un nuevo notificador de Discord
+func NewDiscordNotifier(options DiscordOptions) (*DiscordNotifier, error) {
+	if options.WebhookURL == "" {
+		return nil, fmt.Errorf("discord webhook URL is required")
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "discord",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load discord template: %w", err)
+	}
+
+	return &DiscordNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (dn *DiscordNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	_, jsonPayload, err := dn.build(data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := postJSON(ctx, dn.client, dn.options.WebhookURL, jsonPayload)
+	if err != nil {
+		return fmt.Errorf("failed to send discord message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("discord", resp)
+}
+
+// Preview implementa la interfaz Previewer
+func (dn *DiscordNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, jsonPayload, err := dn.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{jsonPayload}}, nil
+}
+
+// build genera el mensaje y el cuerpo JSON de la petición
+func (dn *DiscordNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := dn.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	// Discord rechaza mensajes que superan el límite en lugar de cortarlos
+	if runes := []rune(message); len(runes) > discordMaxContent {
+		message = string(runes[:discordMaxContent-1]) + "…"
+	}
+
+	payload := map[string]string{"content": message}
+	if dn.options.Username != "" {
+		payload["username"] = dn.options.Username
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return message, jsonPayload, nil
+}
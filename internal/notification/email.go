@@ -0,0 +1,170 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// EmailOptions configuración del notificador por correo electrónico
+type EmailOptions struct {
+	Host         string
+	Port         int
+	Username     string
+	Password     string
+	From         string
+	To           []string
+	Subject      string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// EmailNotifier implementa Observer enviando el reporte por SMTP
+type EmailNotifier struct {
+	options   EmailOptions
+	templates *templates.Set
+}
+
+// NewEmailNotifier crea un nuevo notificador por correo electrónico
+func NewEmailNotifier(options EmailOptions) (*EmailNotifier, error) {
+	if options.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	if options.From == "" {
+		return nil, fmt.Errorf("smtp sender address is required")
+	}
+	if len(options.To) == 0 {
+		return nil, fmt.Errorf("at least one smtp recipient is required")
+	}
+	if options.Port == 0 {
+		options.Port = 587
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "email",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email template: %w", err)
+	}
+
+	return &EmailNotifier{
+		options:   options,
+		templates: tmpl,
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (en *EmailNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	_, msg, err := en.build(data)
+	if err != nil {
+		return err
+	}
+
+	if err := en.sendMail(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// Preview implementa la interfaz Previewer con el mensaje MIME completo
+func (en *EmailNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, msg, err := en.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{msg}}, nil
+}
+
+// build genera el texto y el mensaje MIME con sus cabeceras
+func (en *EmailNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := en.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	subject := en.options.Subject
+	if subject == "" {
+		subject = messageTitle(data)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", en.options.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(en.options.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+
+	return message, buf.Bytes(), nil
+}
+
+// sendMail equivale a smtp.SendMail pero respeta el deadline del contexto
+func (en *EmailNotifier) sendMail(ctx context.Context, msg []byte) error {
+	addr := net.JoinHostPort(en.options.Host, strconv.Itoa(en.options.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, en.options.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: en.options.Host}); err != nil {
+			return err
+		}
+	}
+	if en.options.Username != "" {
+		auth := smtp.PlainAuth("", en.options.Username, en.options.Password, en.options.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(en.options.From); err != nil {
+		return err
+	}
+	for _, to := range en.options.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
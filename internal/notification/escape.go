@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// Modos de formato soportados por la API de Telegram
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeNone       = ""
+)
+
+// ParseTelegramParseMode normaliza el parse mode configurado (sin distinguir
+// mayúsculas); vacío equivale a HTML y "none" desactiva el formato
+func ParseTelegramParseMode(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "", "html":
+		return ParseModeHTML, nil
+	case "markdownv2":
+		return ParseModeMarkdownV2, nil
+	case "none":
+		return ParseModeNone, nil
+	default:
+		return "", fmt.Errorf("unsupported telegram parse mode %q", value)
+	}
+}
+
+// escaperFor devuelve la función de escapado para un modo de formato
+func escaperFor(parseMode string) func(string) string {
+	switch parseMode {
+	case ParseModeHTML:
+		return templates.EscapeHTML
+	case ParseModeMarkdownV2:
+		return templates.EscapeMarkdownV2
+	default:
+		return func(s string) string { return s }
+	}
+}
+
+// textCheckerFor devuelve la validación del texto literal de las plantillas
+// propias para un modo de formato, o nil si no hace falta
+func textCheckerFor(parseMode string) func(string) error {
+	if parseMode == ParseModeMarkdownV2 {
+		return templates.CheckMarkdownV2
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+const (
+	// ExecPayloadVersion versión del JSON que reciben los comandos por stdin;
+	// se incrementa solo con cambios incompatibles
+	ExecPayloadVersion = 1
+
+	// defaultExecTimeout solo se aplica si el contexto no trae plazo; el
+	// NotificationManager ya limita cada notificador con su timeout
+	defaultExecTimeout = 30 * time.Second
+	// execWaitDelay espera a que se cierren stdout/stderr tras matar el proceso
+	execWaitDelay = 5 * time.Second
+	// execMaxStderr bytes de stderr que se conservan para el mensaje de error
+	execMaxStderr = 4096
+)
+
+// ExecOptions configuración del notificador que ejecuta un comando externo
+type ExecOptions struct {
+	// Command programa y argumentos; se ejecuta sin shell
+	Command []string
+	// Env variables de entorno adicionales para el comando
+	Env map[string]string
+	// Dir directorio de trabajo (por defecto el actual)
+	Dir          string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// ExecNotifier implementa Observer ejecutando un comando que recibe el
+// reporte en JSON por stdin
+type ExecNotifier struct {
+	options   ExecOptions
+	templates *templates.Set
+}
+
+// ExecPayload JSON que recibe el comando por stdin
+type ExecPayload struct {
+	Version  int                `json:"version"`
+	Event    model.EventType    `json:"event"`
+	Severity string             `json:"severity"`
+	Hostname string             `json:"hostname"`
+	Title    string             `json:"title"`
+	Message  string             `json:"message"`
+	Report   *model.CheckReport `json:"report"`
+	Changes  *model.ChangeSet   `json:"changes,omitempty"`
+}
+
+// NewExecNotifier crea un nuevo notificador por comando externo
+func NewExecNotifier(options ExecOptions) (*ExecNotifier, error) {
+	if len(options.Command) == 0 || options.Command[0] == "" {
+		return nil, fmt.Errorf("exec command is required")
+	}
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "exec",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exec template: %w", err)
+	}
+
+	return &ExecNotifier{
+		options:   options,
+		templates: tmpl,
+	}, nil
+}
+
+// Notify implementa la interfaz Observer. Un código de salida distinto de
+// cero se considera un envío fallido e incluye la salida de error del comando.
+// El comando se mata cuando vence el plazo del contexto.
+func (en *ExecNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	payload, _, err := en.build(data)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultExecTimeout)
+		defer cancel()
+	}
+	started := time.Now()
+
+	cmd := exec.CommandContext(ctx, en.options.Command[0], en.options.Command[1:]...)
+	cmd.Dir = en.options.Dir
+	cmd.Env = append(os.Environ(), en.environment(data, payload)...)
+	cmd.Stdin = bytes.NewReader(payload)
+	stderr := &limitedBuffer{max: execMaxStderr}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = execWaitDelay
+	killProcessGroup(cmd)
+
+	err = cmd.Run()
+	msg := strings.TrimSpace(stderr.String())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		elapsed := time.Since(started).Round(time.Millisecond)
+		if msg != "" {
+			return fmt.Errorf("command %s timed out after %s: %s", en.options.Command[0], elapsed, msg)
+		}
+		return fmt.Errorf("command %s timed out after %s", en.options.Command[0], elapsed)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && msg != "" {
+			return fmt.Errorf("command %s failed with exit code %d: %s", en.options.Command[0], exitErr.ExitCode(), msg)
+		}
+		return fmt.Errorf("command %s failed: %w", en.options.Command[0], err)
+	}
+	return nil
+}
+
+// Preview implementa la interfaz Previewer con el JSON que recibiría el comando
+func (en *ExecNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	payload, message, err := en.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{payload}}, nil
+}
+
+// build genera el JSON que se escribe en stdin y el mensaje de la plantilla
+func (en *ExecNotifier) build(data *model.NotificationData) ([]byte, string, error) {
+	message, err := en.templates.Render(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	payload, err := json.Marshal(ExecPayload{
+		Version:  ExecPayloadVersion,
+		Event:    data.Event(),
+		Severity: SeverityOf(data).String(),
+		Hostname: data.Hostname,
+		Title:    messageTitle(data),
+		Message:  message,
+		Report:   data.Report,
+		Changes:  data.Changes,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return payload, message, nil
+}
+
+// environment variables DIC_* con los metadatos de la notificación, seguidas
+// de las configuradas (que pueden sobrescribirlas)
+func (en *ExecNotifier) environment(data *model.NotificationData, payload []byte) []string {
+	env := []string{
+		"DIC_PAYLOAD_VERSION=" + strconv.Itoa(ExecPayloadVersion),
+		"DIC_EVENT=" + string(data.Event()),
+		"DIC_SEVERITY=" + SeverityOf(data).String(),
+		"DIC_HOSTNAME=" + data.Hostname,
+		"DIC_PAYLOAD_SIZE=" + strconv.Itoa(len(payload)),
+	}
+	if report := data.Report; report != nil {
+		env = append(env,
+			"DIC_TOTAL="+strconv.Itoa(report.Total),
+			"DIC_AVAILABLE="+strconv.Itoa(len(report.Available)),
+			"DIC_FAILED="+strconv.Itoa(len(report.Failed)),
+			"DIC_UP_TO_DATE="+strconv.Itoa(len(report.UpToDate)),
+		)
+	}
+	for key, value := range en.options.Env {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+// limitedBuffer conserva como máximo los primeros max bytes escritos. No
+// embebe bytes.Buffer para no heredar ReadFrom, que io.Copy usaría saltándose
+// el límite.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+// Write descarta lo que exceda el límite sin devolver error al proceso
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if room := lb.max - lb.buf.Len(); room > 0 {
+		if len(p) > room {
+			lb.buf.Write(p[:room])
+		} else {
+			lb.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// String devuelve lo conservado
+func (lb *limitedBuffer) String() string {
+	return lb.buf.String()
+}
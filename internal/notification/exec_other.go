@@ -0,0 +1,9 @@
+//go:build !unix
+
+package notification
+
+import "os/exec"
+
+// killProcessGroup no hace nada fuera de unix: al cancelar solo se mata el
+// proceso principal
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,166 @@
+//go:build unix
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript crea un script de shell ejecutable en un directorio temporal
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestExec crea un notificador que ejecuta el script indicado
+func newTestExec(t *testing.T, options ExecOptions, script string) *ExecNotifier {
+	t.Helper()
+	options.Command = append([]string{writeScript(t, script)}, options.Command...)
+	notifier, err := NewExecNotifier(options)
+	if err != nil {
+		t.Fatalf("NewExecNotifier: %v", err)
+	}
+	return notifier
+}
+
+func TestExecNotifierExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name:   "success",
+			script: "cat > /dev/null",
+		},
+		{
+			name:   "stderr is reported",
+			script: "cat > /dev/null\necho 'webhook returned 500' >&2\nexit 3",
+			want:   "failed with exit code 3: webhook returned 500",
+		},
+		{
+			name:   "no stderr",
+			script: "exit 2",
+			want:   "failed: exit status 2",
+		},
+		{
+			name:   "stderr is truncated",
+			script: "head -c 10000 /dev/zero | tr '\\0' x >&2\nexit 1",
+			want:   "failed with exit code 1: " + strings.Repeat("x", execMaxStderr),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := newTestExec(t, ExecOptions{}, tt.script)
+			err := notifier.Notify(context.Background(), mqttTestData())
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Notify: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+				t.Errorf("Notify error = %.200v, want suffix %.200q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecNotifierTimeout(t *testing.T) {
+	notifier := newTestExec(t, ExecOptions{}, "echo 'still waiting' >&2\nsleep 10")
+
+	// El plazo lo pone el NotificationManager con el timeout del notificador
+	manager := NewNotificationManager()
+	manager.SetProgress(io.Discard)
+	manager.SetTimeout("script", 200*time.Millisecond)
+
+	started := time.Now()
+	err := manager.NotifyOne(context.Background(), WithName("script", notifier), mqttTestData())
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Notify returned after %s, the process group was not killed", elapsed)
+	}
+	for _, want := range []string{"script: ", "timed out after", "still waiting"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestExecNotifierEnvironment(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	dir := t.TempDir()
+	notifier := newTestExec(t, ExecOptions{
+		Command: []string{out},
+		Dir:     dir,
+		Env:     map[string]string{"WEBHOOK": "https://example.com/hook", "DIC_HOSTNAME": "override"},
+	}, `env > "$1.env"; pwd > "$1.pwd"; cat > "$1.json"`)
+
+	if err := notifier.Notify(context.Background(), mqttTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	env, err := os.ReadFile(out + ".env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(env), "\n")
+	for _, want := range []string{
+		"DIC_PAYLOAD_VERSION=1",
+		"DIC_EVENT=updates",
+		"DIC_SEVERITY=updates",
+		"DIC_TOTAL=1",
+		"DIC_AVAILABLE=1",
+		"DIC_FAILED=0",
+		"DIC_UP_TO_DATE=0",
+		"WEBHOOK=https://example.com/hook",
+		// Las variables configuradas sobrescriben las del notificador
+		"DIC_HOSTNAME=override",
+	} {
+		found := false
+		for _, line := range lines {
+			found = found || line == want
+		}
+		if !found {
+			t.Errorf("environment does not contain %s", want)
+		}
+	}
+
+	pwd, err := os.ReadFile(out + ".pwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotDir, _ := filepath.EvalSymlinks(strings.TrimSpace(string(pwd)))
+	wantDir, _ := filepath.EvalSymlinks(dir)
+	assertEqual(t, gotDir, wantDir)
+
+	stdin, err := os.ReadFile(out + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload ExecPayload
+	if err := json.Unmarshal(stdin, &payload); err != nil {
+		t.Fatalf("invalid stdin payload %q: %v", stdin, err)
+	}
+	assertEqual(t, payload.Version, ExecPayloadVersion)
+	assertEqual(t, payload.Hostname, "host")
+	assertEqual(t, payload.Report.Available[0].Container.Name, "web")
+	if !strings.Contains(string(env), "DIC_PAYLOAD_SIZE="+strconv.Itoa(len(stdin))) {
+		t.Errorf("DIC_PAYLOAD_SIZE does not match the %d bytes of stdin", len(stdin))
+	}
+}
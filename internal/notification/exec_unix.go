@@ -0,0 +1,17 @@
+//go:build unix
+
+package notification
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup ejecuta el comando en su propio grupo de procesos para que
+// al cancelarlo se terminen también los procesos que haya lanzado
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,142 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// GotifyOptions configuración del notificador de Gotify
+type GotifyOptions struct {
+	// ServerURL URL base del servidor Gotify (ej: https://gotify.example.com)
+	ServerURL string
+	// AppToken token de la aplicación que publica los mensajes
+	AppToken string
+	// Priority fija la prioridad (1-10); si es 0 se deriva del reporte
+	Priority int
+	// Markdown indica a los clientes que rendericen el mensaje como markdown
+	Markdown     bool
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// GotifyNotifier implementa Observer para notificaciones push via Gotify
+type GotifyNotifier struct {
+	options   GotifyOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// NewGotifyNotifier crea un nuevo notificador de Gotify
+func NewGotifyNotifier(options GotifyOptions) (*GotifyNotifier, error) {
+	if options.ServerURL == "" {
+		return nil, fmt.Errorf("gotify server URL is required")
+	}
+	if options.AppToken == "" {
+		return nil, fmt.Errorf("gotify app token is required")
+	}
+	if options.Priority < 0 || options.Priority > 10 {
+		return nil, fmt.Errorf("gotify priority must be between 1 and 10, got %d", options.Priority)
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "gotify",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gotify template: %w", err)
+	}
+
+	return &GotifyNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (gn *GotifyNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	_, jsonPayload, err := gn.build(data)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimRight(gn.options.ServerURL, "/") + "/message"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create gotify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", gn.options.AppToken)
+
+	resp, err := gn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send gotify message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("gotify", resp)
+}
+
+// Preview implementa la interfaz Previewer
+func (gn *GotifyNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, jsonPayload, err := gn.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{jsonPayload}}, nil
+}
+
+// build genera el mensaje y el cuerpo JSON de la petición
+func (gn *GotifyNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := gn.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"title":    messageTitle(data),
+		"message":  message,
+		"priority": gn.priority(data),
+	}
+	if gn.options.Markdown {
+		payload["extras"] = map[string]interface{}{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		}
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return message, jsonPayload, nil
+}
+
+// priority devuelve la prioridad configurada o la derivada del reporte
+func (gn *GotifyNotifier) priority(data *model.NotificationData) int {
+	if gn.options.Priority > 0 {
+		return gn.options.Priority
+	}
+
+	// Los clientes Android de Gotify solo emiten sonido a partir de 4 y
+	// muestran la notificación como urgente a partir de 8
+	switch SeverityOf(data) {
+	case SeverityFailure:
+		return 8
+	case SeverityMajor:
+		return 7
+	case SeverityUpdates:
+		return 5
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout es el timeout usado por los notificadores HTTP
+const defaultHTTPTimeout = 10 * time.Second
+
+// newHTTPClient crea el cliente HTTP compartido por los notificadores
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// postJSON envía un cuerpo JSON respetando la cancelación del contexto
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return client.Do(req)
+}
+
+// checkResponse convierte una respuesta no exitosa en un error con el cuerpo recibido
+func checkResponse(service string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("%s API returned status code %d: %s", service, resp.StatusCode, msg)
+	}
+	return fmt.Errorf("%s API returned status code: %d", service, resp.StatusCode)
+}
@@ -1,56 +1,401 @@
 package notification
 
-import "github.com/pablopin/docker-image-checker/internal/model"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/metrics"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// defaultNotifyTimeout tiempo máximo de cada notificador si no se configura otro
+const defaultNotifyTimeout = 30 * time.Second
 
 // Observer define la interfaz para observadores de notificaciones
 type Observer interface {
-	Notify(data *model.NotificationData) error
+	Notify(ctx context.Context, data *model.NotificationData) error
+}
+
+// Named lo implementan los observers identificados por un nombre, usado
+// por las reglas de enrutado
+type Named interface {
+	Name() string
 }
 
 // Subject define la interfaz para el sujeto observado
 type Subject interface {
 	Subscribe(observer Observer)
 	Unsubscribe(observer Observer)
-	NotifyAll(data *model.NotificationData) error
+	NotifyAll(ctx context.Context, data *model.NotificationData) error
+}
+
+// namedObserver asocia un nombre a un Observer
+type namedObserver struct {
+	Observer
+	name string
+}
+
+// Name implementa la interfaz Named
+func (no *namedObserver) Name() string {
+	return no.name
+}
+
+// WithName devuelve el observer identificado con el nombre indicado
+func WithName(name string, observer Observer) Observer {
+	return &namedObserver{Observer: observer, name: name}
+}
+
+// ObserverName devuelve el nombre de un observer o "" si no tiene
+func ObserverName(observer Observer) string {
+	if named, ok := observer.(Named); ok {
+		return named.Name()
+	}
+	return ""
 }
 
-// NotificationManager implementa el patrón Observer para notificaciones
+// displayName nombre de un observer para logs y errores
+func displayName(observer Observer) string {
+	if name := ObserverName(observer); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", observer)
+}
+
+// NotificationManager implementa el patrón Observer para notificaciones.
+// Es seguro para uso concurrente.
 type NotificationManager struct {
-	observers []Observer
+	mu             sync.RWMutex
+	observers      []Observer
+	router         *Router
+	outbox         *Outbox
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+	progress       io.Writer
 }
 
 // NewNotificationManager crea un nuevo manager de notificaciones
 func NewNotificationManager() *NotificationManager {
 	return &NotificationManager{
-		observers: make([]Observer, 0),
+		observers:      make([]Observer, 0),
+		defaultTimeout: defaultNotifyTimeout,
+		timeouts:       make(map[string]time.Duration),
+		progress:       os.Stdout,
 	}
 }
 
 // Subscribe añade un observer
 func (nm *NotificationManager) Subscribe(observer Observer) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
 	nm.observers = append(nm.observers, observer)
 }
 
 // Unsubscribe remueve un observer
 func (nm *NotificationManager) Unsubscribe(observer Observer) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
 	for i, obs := range nm.observers {
 		if obs == observer {
-			nm.observers = append(nm.observers[:i], nm.observers[i+1:]...)
+			// Se crea un slice nuevo para no modificar el que esté usando NotifyAll
+			observers := make([]Observer, 0, len(nm.observers)-1)
+			observers = append(observers, nm.observers[:i]...)
+			nm.observers = append(observers, nm.observers[i+1:]...)
 			break
 		}
 	}
 }
 
-// NotifyAll notifica a todos los observers
-func (nm *NotificationManager) NotifyAll(data *model.NotificationData) error {
-	var lastError error
-	
+// Observers devuelve los observers suscritos
+func (nm *NotificationManager) Observers() []Observer {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	return append([]Observer(nil), nm.observers...)
+}
+
+// SetRouter configura las reglas de enrutado; nil envía todo a todos
+func (nm *NotificationManager) SetRouter(router *Router) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.router = router
+}
+
+// SetOutbox activa la entrega persistente con reintentos; nil entrega directamente
+func (nm *NotificationManager) SetOutbox(outbox *Outbox) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.outbox = outbox
+}
+
+// SetProgress fija el destino de los mensajes de progreso; nil usa stdout
+func (nm *NotificationManager) SetProgress(w io.Writer) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.progress = progressOutput(w)
+}
+
+// SetDefaultTimeout fija el tiempo máximo de los notificadores sin timeout propio
+func (nm *NotificationManager) SetDefaultTimeout(timeout time.Duration) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if timeout > 0 {
+		nm.defaultTimeout = timeout
+	}
+}
+
+// SetTimeout fija el tiempo máximo de un notificador por su nombre
+func (nm *NotificationManager) SetTimeout(name string, timeout time.Duration) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if timeout > 0 {
+		nm.timeouts[name] = timeout
+	}
+}
+
+// NotifyAll notifica a todos los observers en paralelo, cada uno con su
+// timeout, y devuelve todos los errores agrupados con el nombre del
+// notificador. Con outbox, cada notificación se encola por observer antes de
+// intentar entregarla: los fallos de entrega se reintentan más tarde y solo
+// se devuelve error si no se pudo encolar.
+func (nm *NotificationManager) NotifyAll(ctx context.Context, data *model.NotificationData) error {
+	return nm.NotifyMatching(ctx, data, nil)
+}
+
+// NotifyMatching funciona como NotifyAll pero solo notifica a los observers
+// cuyo nombre acepta allow (nil acepta todos). Si algún observer recibió la
+// notificación y otros fallaron, el error es un *PartialError.
+func (nm *NotificationManager) NotifyMatching(ctx context.Context, data *model.NotificationData, allow func(name string) bool) error {
+	var (
+		errs      []error
+		delivered bool
+	)
+	for _, result := range nm.notifyEach(ctx, data, allow) {
+		errs = append(errs, result.err)
+		delivered = delivered || result.err == nil
+	}
+	return partial(errors.Join(errs...), delivered)
+}
+
+// PartialError indica que la notificación llegó al menos a un notificador;
+// Err agrupa los errores de los que fallaron
+type PartialError struct {
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Delivered indica, a partir del error de NotifyAll, si la notificación llegó
+// (o quedó encolada o retenida) para al menos un notificador
+func Delivered(err error) bool {
+	var partialErr *PartialError
+	return err == nil || errors.As(err, &partialErr)
+}
+
+// partial marca err como *PartialError si hubo alguna entrega
+func partial(err error, delivered bool) error {
+	if err == nil || !delivered {
+		return err
+	}
+	return &PartialError{Err: err}
+}
+
+// withoutPartial quita la marca de entrega parcial de un error, para que no
+// se confunda con la de otro envío al agruparlo
+func withoutPartial(err error) error {
+	if partialErr, ok := err.(*PartialError); ok {
+		return partialErr.Err
+	}
+	return err
+}
+
+// deliveryResult resultado de notificar a un observer; con outbox, encolar
+// la notificación cuenta como entrega
+type deliveryResult struct {
+	name string
+	err  error
+}
+
+// notifyEach implementa NotifyMatching y devuelve el resultado de cada
+// observer notificado
+func (nm *NotificationManager) notifyEach(ctx context.Context, data *model.NotificationData, allow func(name string) bool) []deliveryResult {
+	nm.mu.RLock()
+	observers, router, outbox, progress := nm.observers, nm.router, nm.outbox, nm.progress
+	nm.mu.RUnlock()
+
+	var (
+		results []deliveryResult
+		direct  []func() error
+		names   []string
+	)
+	for _, observer := range observers {
+		name := ObserverName(observer)
+		if allow != nil && !allow(name) {
+			continue
+		}
+
+		observerData := data
+		if router != nil {
+			var ok bool
+			observerData, ok = router.Filter(name, data)
+			if !ok {
+				continue
+			}
+		}
+
+		if outbox != nil && name != "" {
+			results = append(results, deliveryResult{name: name, err: outbox.Enqueue(name, observerData)})
+			continue
+		}
+
+		names = append(names, name)
+		direct = append(direct, func() error {
+			return nm.notify(ctx, observer, observerData)
+		})
+	}
+
+	for i, err := range runConcurrently(direct) {
+		results = append(results, deliveryResult{name: names[i], err: err})
+	}
+
+	if outbox != nil {
+		if err := nm.DeliverPending(ctx); err != nil {
+			fmt.Fprintln(progress, i18n.T("notify.pending_retry", err))
+		}
+	}
+
+	return results
+}
+
+// NotifyOne notifica a un único observer con su timeout, sin enrutado ni outbox
+func (nm *NotificationManager) NotifyOne(ctx context.Context, observer Observer, data *model.NotificationData) error {
+	return nm.notify(ctx, observer, data)
+}
+
+// notify invoca un observer con su timeout y etiqueta el error con su nombre
+func (nm *NotificationManager) notify(ctx context.Context, observer Observer, data *model.NotificationData) error {
+	ctx, cancel := context.WithTimeout(ctx, nm.timeoutFor(ObserverName(observer)))
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "Observer.Notify",
+		attribute.String("notifier.name", displayName(observer)),
+		attribute.String("notification.event", string(data.Event())),
+	)
+	err := observer.Notify(ctx, data)
+	tracing.End(span, err)
+
+	if err != nil {
+		metrics.NotificationDeliveries.WithLabelValues(displayName(observer), metrics.ResultFailure).Inc()
+		return fmt.Errorf("%s: %w", displayName(observer), err)
+	}
+	metrics.NotificationDeliveries.WithLabelValues(displayName(observer), metrics.ResultSuccess).Inc()
+	return nil
+}
+
+// timeoutFor devuelve el timeout configurado para un notificador
+func (nm *NotificationManager) timeoutFor(name string) time.Duration {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+	if timeout, ok := nm.timeouts[name]; ok {
+		return timeout
+	}
+	return nm.defaultTimeout
+}
+
+// progressOutput devuelve el destino de los mensajes de progreso, stdout si es nil
+func progressOutput(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// runConcurrently ejecuta las funciones en paralelo y devuelve el error de
+// cada una, en el mismo orden
+func runConcurrently(funcs []func() error) []error {
+	results := make([]error, len(funcs))
+
+	var wg sync.WaitGroup
+	for i, fn := range funcs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fn()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// DeliverPending entrega las notificaciones del outbox cuyo reintento ha vencido
+func (nm *NotificationManager) DeliverPending(ctx context.Context) error {
+	nm.mu.RLock()
+	outbox := nm.outbox
+	nm.mu.RUnlock()
+
+	if outbox == nil {
+		return nil
+	}
+	return outbox.Deliver(ctx, func(ctx context.Context, name string, data *model.NotificationData) error {
+		observer := nm.observerByName(name)
+		if observer == nil {
+			return errUnknownNotifier
+		}
+		return nm.notify(ctx, observer, data)
+	})
+}
+
+// RunOutbox reintenta periódicamente las notificaciones pendientes hasta que
+// se cancela el contexto; no hace nada si no hay outbox
+func (nm *NotificationManager) RunOutbox(ctx context.Context) {
+	nm.mu.RLock()
+	outbox := nm.outbox
+	nm.mu.RUnlock()
+
+	if outbox == nil {
+		return
+	}
+
+	ticker := time.NewTicker(outbox.RetryInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := nm.DeliverPending(ctx); err != nil {
+				stats := outbox.Stats()
+				log.Printf("Outbox: %d pending, %d delivered, %d retried, %d expired",
+					stats.Pending, stats.Delivered, stats.Retried, stats.Expired)
+			}
+		}
+	}
+}
+
+// observerByName busca un observer suscrito por su nombre
+func (nm *NotificationManager) observerByName(name string) Observer {
+	nm.mu.RLock()
+	defer nm.mu.RUnlock()
+
 	for _, observer := range nm.observers {
-		if err := observer.Notify(data); err != nil {
-			lastError = err
-			// Log error but continue with other observers
+		if ObserverName(observer) == name {
+			return observer
 		}
 	}
-	
-	return lastError
+	return nil
 }
@@ -0,0 +1,269 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// fakeObserver observer de prueba que espera wait (si no es nil) o a que
+// venza el contexto, y devuelve err
+type fakeObserver struct {
+	wait  chan struct{}
+	err   error
+	calls atomic.Int32
+}
+
+func (f *fakeObserver) Notify(ctx context.Context, data *model.NotificationData) error {
+	f.calls.Add(1)
+	if f.wait != nil {
+		select {
+		case <-f.wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return f.err
+}
+
+// newTestManager crea un manager sin mensajes de progreso con los observers indicados
+func newTestManager(observers map[string]Observer) *NotificationManager {
+	manager := NewNotificationManager()
+	manager.SetProgress(io.Discard)
+	for name, observer := range observers {
+		manager.Subscribe(WithName(name, observer))
+	}
+	return manager
+}
+
+// barrierObserver solo termina cuando todos los observers de la barrera
+// han empezado, así que únicamente funciona si se notifican en paralelo
+type barrierObserver struct {
+	arrived *sync.WaitGroup
+	all     chan struct{}
+}
+
+func (b *barrierObserver) Notify(ctx context.Context, data *model.NotificationData) error {
+	b.arrived.Done()
+	select {
+	case <-b.all:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestNotifyAllConcurrent(t *testing.T) {
+	const n = 4
+	var arrived sync.WaitGroup
+	arrived.Add(n)
+	all := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(all)
+	}()
+
+	observers := make(map[string]Observer)
+	for i := 0; i < n; i++ {
+		observers[fmt.Sprintf("observer-%d", i)] = &barrierObserver{arrived: &arrived, all: all}
+	}
+	manager := newTestManager(observers)
+	manager.SetDefaultTimeout(5 * time.Second)
+
+	if err := manager.NotifyAll(context.Background(), mqttTestData()); err != nil {
+		t.Fatalf("observers were not notified concurrently: %v", err)
+	}
+}
+
+func TestNotifyAllErrors(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errSecond := errors.New("second failed")
+
+	tests := []struct {
+		name          string
+		observers     map[string]Observer
+		timeouts      map[string]time.Duration
+		wantIs        []error
+		wantContains  []string
+		wantDelivered bool
+	}{
+		{
+			name:          "all delivered",
+			observers:     map[string]Observer{"ops": &fakeObserver{}, "dev": &fakeObserver{}},
+			wantDelivered: true,
+		},
+		{
+			name: "every error carries the notifier name",
+			observers: map[string]Observer{
+				"ops": &fakeObserver{err: errFirst},
+				"dev": &fakeObserver{err: errSecond},
+			},
+			wantIs:       []error{errFirst, errSecond},
+			wantContains: []string{"ops: first failed", "dev: second failed"},
+		},
+		{
+			name: "partial delivery",
+			observers: map[string]Observer{
+				"ops": &fakeObserver{},
+				"dev": &fakeObserver{err: errFirst},
+			},
+			wantIs:        []error{errFirst},
+			wantContains:  []string{"dev: first failed"},
+			wantDelivered: true,
+		},
+		{
+			// Solo vence el timeout del notificador lento; el rápido entrega
+			name: "per notifier timeout",
+			observers: map[string]Observer{
+				"slow": &fakeObserver{wait: make(chan struct{})},
+				"fast": &fakeObserver{},
+			},
+			timeouts:      map[string]time.Duration{"slow": 50 * time.Millisecond},
+			wantIs:        []error{context.DeadlineExceeded},
+			wantContains:  []string{"slow: context deadline exceeded"},
+			wantDelivered: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(tt.observers)
+			manager.SetDefaultTimeout(5 * time.Second)
+			for name, timeout := range tt.timeouts {
+				manager.SetTimeout(name, timeout)
+			}
+
+			err := manager.NotifyAll(context.Background(), mqttTestData())
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("error %v does not wrap %v", err, target)
+				}
+			}
+			for _, want := range tt.wantContains {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("error %v does not contain %q", err, want)
+				}
+			}
+			if len(tt.wantIs) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			assertEqual(t, Delivered(err), tt.wantDelivered)
+
+			var partialErr *PartialError
+			assertEqual(t, errors.As(err, &partialErr), err != nil && tt.wantDelivered)
+		})
+	}
+}
+
+func TestNotifyMatching(t *testing.T) {
+	ops, dev := &fakeObserver{}, &fakeObserver{err: errors.New("down")}
+	manager := newTestManager(map[string]Observer{"ops": ops, "dev": dev})
+
+	err := manager.NotifyMatching(context.Background(), mqttTestData(), func(name string) bool {
+		return name == "ops"
+	})
+	if err != nil {
+		t.Fatalf("NotifyMatching: %v", err)
+	}
+	assertEqual(t, ops.calls.Load(), int32(1))
+	assertEqual(t, dev.calls.Load(), int32(0))
+}
+
+func TestDelivered(t *testing.T) {
+	failed := errors.New("failed")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: true},
+		{name: "error", err: failed, want: false},
+		{name: "partial", err: &PartialError{Err: failed}, want: true},
+		{name: "wrapped partial", err: fmt.Errorf("notify: %w", &PartialError{Err: failed}), want: true},
+		{name: "partial helper without delivery", err: partial(failed, false), want: false},
+		{name: "partial helper with delivery", err: partial(failed, true), want: true},
+		{name: "without partial", err: withoutPartial(partial(failed, true)), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqual(t, Delivered(tt.err), tt.want)
+		})
+	}
+
+	if partial(nil, true) != nil {
+		t.Error("partial(nil, true) must stay nil")
+	}
+	if err := (&PartialError{Err: failed}); !errors.Is(err, failed) || err.Error() != "failed" {
+		t.Errorf("PartialError does not wrap its error: %v", err)
+	}
+}
+
+func TestSubscribeDuringNotify(t *testing.T) {
+	release := make(chan struct{})
+	slow := &fakeObserver{wait: release}
+	manager := newTestManager(map[string]Observer{"slow": slow})
+
+	done := make(chan error)
+	go func() { done <- manager.NotifyAll(context.Background(), mqttTestData()) }()
+
+	// Mientras el notificador lento sigue en marcha, suscribir y desuscribir
+	// no se bloquea ni cambia el envío en curso
+	for slow.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			observer := WithName(fmt.Sprintf("extra-%d", i), &fakeObserver{})
+			manager.Subscribe(observer)
+			_ = manager.Observers()
+			manager.Unsubscribe(observer)
+		}()
+	}
+	wg.Wait()
+	assertEqual(t, len(manager.Observers()), 1)
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("NotifyAll: %v", err)
+	}
+}
+
+// unsubscribingObserver se da de baja desde su propio Notify
+type unsubscribingObserver struct {
+	manager *NotificationManager
+	self    Observer
+}
+
+func (u *unsubscribingObserver) Notify(ctx context.Context, data *model.NotificationData) error {
+	u.manager.Unsubscribe(u.self)
+	return nil
+}
+
+func TestUnsubscribeFromNotify(t *testing.T) {
+	manager := newTestManager(nil)
+	other := &fakeObserver{}
+	observer := &unsubscribingObserver{manager: manager}
+	observer.self = WithName("once", observer)
+	manager.Subscribe(observer.self)
+	manager.Subscribe(WithName("other", other))
+
+	for i := 0; i < 2; i++ {
+		if err := manager.NotifyAll(context.Background(), mqttTestData()); err != nil {
+			t.Fatalf("NotifyAll: %v", err)
+		}
+	}
+	assertEqual(t, other.calls.Load(), int32(2))
+	assertEqual(t, len(manager.Observers()), 1)
+}
@@ -0,0 +1,146 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// MatrixOptions configuración del notificador de Matrix
+type MatrixOptions struct {
+	// HomeserverURL URL base del homeserver (ej: https://matrix.example.com)
+	HomeserverURL string
+	// RoomID identificador de la sala (ej: !abcdef:example.com)
+	RoomID       string
+	AccessToken  string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// MatrixNotifier implementa Observer publicando mensajes en una sala de Matrix
+type MatrixNotifier struct {
+	options   MatrixOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// NewMatrixNotifier crea un nuevo notificador de Matrix
+func NewMatrixNotifier(options MatrixOptions) (*MatrixNotifier, error) {
+	if options.HomeserverURL == "" {
+		return nil, fmt.Errorf("matrix homeserver URL is required")
+	}
+	if options.RoomID == "" {
+		return nil, fmt.Errorf("matrix room ID is required")
+	}
+	if options.AccessToken == "" {
+		return nil, fmt.Errorf("matrix access token is required")
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "matrix",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load matrix template: %w", err)
+	}
+
+	return &MatrixNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (mn *MatrixNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	message, jsonPayload, err := mn.build(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, mn.sendURL(mn.transactionID(data, message)), bytes.NewReader(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+mn.options.AccessToken)
+
+	resp, err := mn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send matrix message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("matrix", resp)
+}
+
+// Preview implementa la interfaz Previewer
+func (mn *MatrixNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, jsonPayload, err := mn.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{jsonPayload}}, nil
+}
+
+// build genera el mensaje y el cuerpo JSON de la petición
+func (mn *MatrixNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := mn.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	payload := map[string]string{
+		"msgtype":        "m.text",
+		"body":           message,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": matrixHTML(message),
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return message, jsonPayload, nil
+}
+
+// sendURL construye la URL del endpoint m.room.message para una transacción
+func (mn *MatrixNotifier) sendURL(txnID string) string {
+	return fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(mn.options.HomeserverURL, "/"),
+		url.PathEscape(mn.options.RoomID),
+		url.PathEscape(txnID),
+	)
+}
+
+// transactionID genera un ID determinista para que los reintentos del mismo
+// reporte no dupliquen mensajes en la sala
+func (mn *MatrixNotifier) transactionID(data *model.NotificationData, message string) string {
+	hash := sha256.New()
+	hash.Write([]byte(mn.options.RoomID))
+	hash.Write([]byte(data.Hostname))
+	if data.Report != nil {
+		hash.Write([]byte(data.Report.Timestamp.UTC().Format("20060102T150405.000000000")))
+	}
+	hash.Write([]byte(message))
+	return "dic-" + hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// matrixHTML convierte el mensaje en texto plano al HTML de formatted_body
+func matrixHTML(message string) string {
+	lines := strings.Split(html.EscapeString(message), "\n")
+	return strings.Join(lines, "<br/>")
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// matrixServer homeserver que guarda la ruta de cada envío y falla con 502
+// las peticiones indicadas en fail (empezando en 1)
+type matrixServer struct {
+	mu    sync.Mutex
+	paths []string
+	fail  map[int]bool
+}
+
+func (m *matrixServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paths = append(m.paths, r.URL.EscapedPath())
+	if m.fail[len(m.paths)] {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"event_id": "$event"})
+}
+
+// transactionOf último segmento de la ruta de envío
+func transactionOf(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func TestMatrixTransactionID(t *testing.T) {
+	homeserver := &matrixServer{fail: map[int]bool{1: true}}
+	server := httptest.NewServer(homeserver)
+	defer server.Close()
+
+	notifier, err := NewMatrixNotifier(MatrixOptions{
+		HomeserverURL: server.URL + "/",
+		RoomID:        "!room:example.com",
+		AccessToken:   "token",
+	})
+	if err != nil {
+		t.Fatalf("NewMatrixNotifier: %v", err)
+	}
+
+	data := mqttTestData()
+	data.Report.Timestamp = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	if err := notifier.Notify(context.Background(), data); err == nil {
+		t.Fatal("expected the first send to fail")
+	}
+	// El reintento del mismo reporte reutiliza la transacción
+	if err := notifier.Notify(context.Background(), data); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	// Un reporte nuevo con el mismo contenido es otra transacción
+	next := mqttTestData()
+	next.Report.Timestamp = data.Report.Timestamp.Add(time.Hour)
+	if err := notifier.Notify(context.Background(), next); err != nil {
+		t.Fatalf("new send: %v", err)
+	}
+
+	if len(homeserver.paths) != 3 {
+		t.Fatalf("got %d requests, want 3", len(homeserver.paths))
+	}
+	wantPrefix := "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/dic-"
+	for _, path := range homeserver.paths {
+		if !strings.HasPrefix(path, wantPrefix) {
+			t.Errorf("path %s, want prefix %s", path, wantPrefix)
+		}
+	}
+	first, retry, other := transactionOf(homeserver.paths[0]), transactionOf(homeserver.paths[1]), transactionOf(homeserver.paths[2])
+	if first != retry {
+		t.Errorf("retry used transaction %s, want %s", retry, first)
+	}
+	if other == first {
+		t.Errorf("a new report reused transaction %s", first)
+	}
+
+	// La sala forma parte de la transacción
+	otherRoom, err := NewMatrixNotifier(MatrixOptions{HomeserverURL: server.URL, RoomID: "!other:example.com", AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("NewMatrixNotifier: %v", err)
+	}
+	message, _, err := notifier.build(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherRoom.transactionID(data, message) == first {
+		t.Error("two rooms share the same transaction ID")
+	}
+}
+
+func TestMatrixHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "plain", message: "web: 1.25.3 → 1.25.4", want: "web: 1.25.3 → 1.25.4"},
+		{name: "line breaks", message: "first\nsecond\n", want: "first<br/>second<br/>"},
+		{name: "html is escaped", message: `<b>web</b> & "api"`, want: "&lt;b&gt;web&lt;/b&gt; &amp; &#34;api&#34;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqual(t, matrixHTML(tt.message), tt.want)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// messageTitle genera el título común de las notificaciones push
+func messageTitle(data *model.NotificationData) string {
+	return fmt.Sprintf("🐳 Docker Image Checker - %s", data.Hostname)
+}
+
+// Severity clasifica la relevancia de un reporte para decidir la prioridad
+type Severity int
+
+const (
+	// SeverityNone no hay actualizaciones ni errores
+	SeverityNone Severity = iota
+	// SeverityUpdates hay actualizaciones menores o de parche
+	SeverityUpdates
+	// SeverityMajor hay al menos una actualización de versión mayor
+	SeverityMajor
+	// SeverityFailure alguna verificación ha fallado
+	SeverityFailure
+)
+
+// SeverityOf calcula la severidad de los datos de una notificación
+func SeverityOf(data *model.NotificationData) Severity {
+	if data == nil || data.Report == nil {
+		return SeverityNone
+	}
+
+	switch {
+	case len(data.Report.Failed) > 0:
+		return SeverityFailure
+	case data.Report.HasMajorUpdates():
+		return SeverityMajor
+	case len(data.Report.Available) > 0:
+		return SeverityUpdates
+	default:
+		return SeverityNone
+	}
+}
+
+// String devuelve el nombre de la severidad (none, updates, major, failure)
+func (s Severity) String() string {
+	switch s {
+	case SeverityUpdates:
+		return "updates"
+	case SeverityMajor:
+		return "major"
+	case SeverityFailure:
+		return "failure"
+	default:
+		return "none"
+	}
+}
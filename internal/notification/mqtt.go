@@ -0,0 +1,394 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	defaultMQTTTopicPrefix     = "docker-image-checker"
+	defaultMQTTDiscoveryPrefix = "homeassistant"
+)
+
+// MQTTOptions configuración del notificador MQTT
+type MQTTOptions struct {
+	// BrokerURL dirección del broker: mqtt://host[:1883] o mqtts://host[:8883]
+	// (también se aceptan tcp://, ssl:// y tls://)
+	BrokerURL string
+	// ClientID identificador de cliente; vacío genera uno aleatorio por conexión
+	ClientID string
+	Username string
+	Password string
+	// TopicPrefix prefijo de los topics de estado (por defecto docker-image-checker)
+	TopicPrefix string
+	// QoS calidad de servicio de las publicaciones (0 o 1)
+	QoS int
+	// Discovery publica la configuración de discovery de Home Assistant para
+	// que cada contenedor aparezca como una entidad update
+	Discovery bool
+	// DiscoveryPrefix prefijo de discovery de Home Assistant (por defecto homeassistant)
+	DiscoveryPrefix string
+	// CAFile certificado de la CA del broker; CertFile y KeyFile el
+	// certificado de cliente si el broker lo exige
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// MQTTNotifier implementa Observer publicando el estado de cada contenedor
+// en topics retenidos de un broker MQTT
+type MQTTNotifier struct {
+	options MQTTOptions
+	address string
+	tls     *tls.Config
+}
+
+// mqttState estado publicado por contenedor. Los campos installed_version,
+// latest_version, title y release_url son los que espera la entidad update
+// de Home Assistant; el resto se expone como atributos.
+type mqttState struct {
+	InstalledVersion string             `json:"installed_version"`
+	LatestVersion    string             `json:"latest_version,omitempty"`
+	Title            string             `json:"title"`
+	ReleaseURL       string             `json:"release_url,omitempty"`
+	Container        string             `json:"container"`
+	Image            string             `json:"image"`
+	Status           model.UpdateStatus `json:"status"`
+	Bump             model.BumpLevel    `json:"bump,omitempty"`
+	Error            string             `json:"error,omitempty"`
+	CheckedAt        time.Time          `json:"checked_at"`
+}
+
+// mqttSummary resumen publicado por host
+type mqttSummary struct {
+	Hostname  string    `json:"hostname"`
+	Total     int       `json:"total"`
+	Available int       `json:"available"`
+	Failed    int       `json:"failed"`
+	UpToDate  int       `json:"up_to_date"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// mqttDiscovery configuración de discovery de una entidad update
+type mqttDiscovery struct {
+	Name                string     `json:"name"`
+	UniqueID            string     `json:"unique_id"`
+	StateTopic          string     `json:"state_topic"`
+	JSONAttributesTopic string     `json:"json_attributes_topic"`
+	Icon                string     `json:"icon"`
+	Device              mqttDevice `json:"device"`
+	Origin              mqttOrigin `json:"origin"`
+}
+
+// mqttDevice dispositivo de Home Assistant que agrupa los contenedores de un host
+type mqttDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+// mqttOrigin identifica a la aplicación que publica el discovery
+type mqttOrigin struct {
+	Name string `json:"name"`
+}
+
+// NewMQTTNotifier crea un nuevo notificador MQTT
+func NewMQTTNotifier(options MQTTOptions) (*MQTTNotifier, error) {
+	if options.BrokerURL == "" {
+		return nil, fmt.Errorf("mqtt broker URL is required")
+	}
+	if options.QoS < 0 || options.QoS > 1 {
+		return nil, fmt.Errorf("mqtt qos must be 0 or 1, got %d", options.QoS)
+	}
+	if options.TopicPrefix == "" {
+		options.TopicPrefix = defaultMQTTTopicPrefix
+	}
+	if options.DiscoveryPrefix == "" {
+		options.DiscoveryPrefix = defaultMQTTDiscoveryPrefix
+	}
+	options.TopicPrefix = strings.Trim(options.TopicPrefix, "/")
+	options.DiscoveryPrefix = strings.Trim(options.DiscoveryPrefix, "/")
+
+	u, err := url.Parse(options.BrokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mqtt broker URL: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid mqtt broker URL %q: missing host", options.BrokerURL)
+	}
+
+	notifier := &MQTTNotifier{options: options}
+	port := u.Port()
+	switch strings.ToLower(u.Scheme) {
+	case "mqtt", "tcp":
+		if port == "" {
+			port = "1883"
+		}
+	case "mqtts", "ssl", "tls":
+		if port == "" {
+			port = "8883"
+		}
+		notifier.tls, err = mqttTLSConfig(u.Hostname(), options)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mqtt broker scheme %q (expected mqtt or mqtts)", u.Scheme)
+	}
+	notifier.address = net.JoinHostPort(u.Hostname(), port)
+
+	return notifier, nil
+}
+
+// mqttTLSConfig prepara la configuración TLS con la CA y el certificado de cliente
+func mqttTLSConfig(serverName string, options MQTTOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if options.CAFile != "" {
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mqtt CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in mqtt CA file %s", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mqtt client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// Notify implementa la interfaz Observer
+func (mn *MQTTNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	messages, err := mn.build(data)
+	if err != nil {
+		return err
+	}
+
+	clientID := mn.options.ClientID
+	if clientID == "" {
+		clientID = randomClientID()
+	}
+	client, err := dialMQTT(ctx, mqttDialOptions{
+		Address:  mn.address,
+		TLS:      mn.tls,
+		ClientID: clientID,
+		Username: mn.options.Username,
+		Password: mn.options.Password,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to mqtt broker %s: %w", mn.address, err)
+	}
+
+	for _, message := range messages {
+		if err := client.Publish(message, byte(mn.options.QoS)); err != nil {
+			return errors.Join(err, client.Close())
+		}
+	}
+	if err := client.Close(); err != nil {
+		return fmt.Errorf("failed to disconnect from mqtt broker: %w", err)
+	}
+	return nil
+}
+
+// Preview implementa la interfaz Previewer; cada payload va precedido de su topic
+func (mn *MQTTNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	messages, err := mn.build(data)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{}
+	var topics []string
+	for _, message := range messages {
+		topics = append(topics, message.Topic)
+		preview.Payloads = append(preview.Payloads, []byte(message.Topic+"\n"+string(message.Payload)))
+	}
+	preview.Message = strings.Join(topics, "\n")
+	return preview, nil
+}
+
+// build genera los mensajes a publicar: el resumen del host, el estado de
+// cada contenedor y, con discovery, su configuración para Home Assistant.
+// Los contenedores que ya no se verifican se borran publicando un mensaje
+// retenido vacío.
+func (mn *MQTTNotifier) build(data *model.NotificationData) ([]mqttMessage, error) {
+	if data.Report == nil {
+		return nil, fmt.Errorf("mqtt notifier requires a report")
+	}
+	report := data.Report
+	hostname := data.Hostname
+	if hostname == "" {
+		hostname = report.Hostname
+	}
+	if hostname == "" {
+		hostname = "localhost"
+	}
+	hostTopic := mn.options.TopicPrefix + "/" + mqttID(hostname)
+
+	var messages []mqttMessage
+	add := func(topic string, value interface{}) error {
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		messages = append(messages, mqttMessage{Topic: topic, Payload: payload, Retain: true})
+		return nil
+	}
+
+	err := add(hostTopic+"/summary", mqttSummary{
+		Hostname:  hostname,
+		Total:     report.Total,
+		Available: len(report.Available),
+		Failed:    len(report.Failed),
+		UpToDate:  len(report.UpToDate),
+		CheckedAt: report.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range [][]model.UpdateInfo{report.Available, report.Failed, report.UpToDate} {
+		for _, update := range list {
+			stateTopic := hostTopic + "/" + mqttID(update.Container.Name) + "/state"
+			if mn.options.Discovery {
+				if err := add(mn.discoveryTopic(hostname, update.Container.Name), mn.discovery(hostname, update.Container.Name, stateTopic)); err != nil {
+					return nil, err
+				}
+			}
+			if err := add(stateTopic, newMQTTState(update, report.Timestamp)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if data.Changes != nil {
+		for _, removed := range data.Changes.Removed {
+			if mn.options.Discovery {
+				messages = append(messages, mqttMessage{Topic: mn.discoveryTopic(hostname, removed.Container.Name), Retain: true})
+			}
+			messages = append(messages, mqttMessage{Topic: hostTopic + "/" + mqttID(removed.Container.Name) + "/state", Retain: true})
+		}
+	}
+
+	return messages, nil
+}
+
+// discoveryTopic <prefix>/update/<host>/<contenedor>/config
+func (mn *MQTTNotifier) discoveryTopic(hostname, container string) string {
+	return fmt.Sprintf("%s/update/%s/%s/config", mn.options.DiscoveryPrefix, mqttID(hostname), mqttID(container))
+}
+
+// discovery genera la configuración de la entidad update de un contenedor
+func (mn *MQTTNotifier) discovery(hostname, container, stateTopic string) mqttDiscovery {
+	return mqttDiscovery{
+		Name:                container,
+		UniqueID:            "docker_image_checker_" + mqttID(hostname) + "_" + mqttID(container),
+		StateTopic:          stateTopic,
+		JSONAttributesTopic: stateTopic,
+		Icon:                "mdi:docker",
+		Device: mqttDevice{
+			Identifiers:  []string{"docker_image_checker_" + mqttID(hostname)},
+			Name:         "Docker " + hostname,
+			Manufacturer: "docker-image-checker",
+			Model:        "Docker host",
+		},
+		Origin: mqttOrigin{Name: "docker-image-checker"},
+	}
+}
+
+// newMQTTState convierte el resultado de un contenedor al estado de la
+// entidad update. Home Assistant considera que hay actualización cuando las
+// versiones instalada y disponible difieren, así que una imagen nueva con el
+// mismo tag se marca explícitamente y un contenedor al día repite su versión.
+func newMQTTState(update model.UpdateInfo, checkedAt time.Time) mqttState {
+	state := mqttState{
+		InstalledVersion: update.CurrentVersion,
+		Title:            update.Container.ImageName,
+		ReleaseURL:       releaseURL(update.Container),
+		Container:        update.Container.Name,
+		Image:            update.Container.ImageName,
+		Status:           update.Status(),
+		CheckedAt:        checkedAt,
+	}
+
+	switch state.Status {
+	case model.StatusFailed:
+		// Sin latest_version Home Assistant conserva el último valor conocido
+		state.Error = update.Error.Error()
+	case model.StatusAvailable:
+		state.LatestVersion = update.LatestVersion
+		if state.LatestVersion == "" || state.LatestVersion == "unknown" || state.LatestVersion == state.InstalledVersion {
+			state.LatestVersion = state.InstalledVersion + " (new image)"
+		}
+		state.Bump = update.Bump()
+	default:
+		state.LatestVersion = state.InstalledVersion
+	}
+	return state
+}
+
+// releaseURL enlace a las versiones de la imagen: la etiqueta OCI de origen si
+// la tiene o, para Docker Hub, su página de tags
+func releaseURL(container model.Container) string {
+	for _, label := range []string{"org.opencontainers.image.url", "org.opencontainers.image.source"} {
+		if value := container.Labels[label]; strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://") {
+			return value
+		}
+	}
+
+	if container.Registry() != "docker.io" {
+		return ""
+	}
+	repository, _, _ := strings.Cut(container.ImageName, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	repository = strings.TrimPrefix(repository, "docker.io/")
+	if name, ok := strings.CutPrefix(repository, "library/"); ok {
+		return "https://hub.docker.com/_/" + name + "/tags"
+	}
+	if !strings.Contains(repository, "/") {
+		return "https://hub.docker.com/_/" + repository + "/tags"
+	}
+	return "https://hub.docker.com/r/" + repository + "/tags"
+}
+
+// mqttID adapta un nombre para usarlo en topics e identificadores de Home
+// Assistant, que solo admiten letras, números, "_" y "-"
+func mqttID(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
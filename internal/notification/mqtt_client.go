@@ -0,0 +1,264 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Cliente MQTT 3.1.1 mínimo: solo conecta, publica (QoS 0 o 1) y desconecta,
+// que es todo lo que necesita un notificador que abre una conexión por envío.
+
+const (
+	mqttConnect    byte = 0x10
+	mqttConnack    byte = 0x20
+	mqttPublish    byte = 0x30
+	mqttPuback     byte = 0x40
+	mqttDisconnect byte = 0xE0
+
+	// mqttKeepAlive intervalo de keep alive anunciado al broker
+	mqttKeepAlive = 60
+	// mqttMaxRemainingLength máximo que admite la codificación de longitud
+	mqttMaxRemainingLength = 268435455
+)
+
+// mqttConnackErrors descripción de los códigos de rechazo de CONNACK
+var mqttConnackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// mqttMessage mensaje a publicar
+type mqttMessage struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// mqttClient conexión con un broker MQTT
+type mqttClient struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	packetID uint16
+}
+
+// mqttDialOptions parámetros de conexión con el broker
+type mqttDialOptions struct {
+	Address  string
+	TLS      *tls.Config
+	ClientID string
+	Username string
+	Password string
+}
+
+// dialMQTT conecta con el broker y completa el handshake CONNECT/CONNACK
+func dialMQTT(ctx context.Context, options mqttDialOptions) (*mqttClient, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", options.Address)
+	if err != nil {
+		return nil, err
+	}
+	if options.TLS != nil {
+		tlsConn := tls.Client(conn, options.TLS)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("tls handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client := &mqttClient{conn: conn, reader: bufio.NewReader(conn)}
+	if err := client.connect(options); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// connect envía CONNECT con sesión limpia y espera el CONNACK
+func (c *mqttClient) connect(options mqttDialOptions) error {
+	var flags byte = 0x02 // clean session
+	if options.Username != "" {
+		flags |= 0x80
+		if options.Password != "" {
+			flags |= 0x40
+		}
+	}
+
+	body, _ := mqttString(nil, "MQTT")
+	body = append(body, 4, flags) // nivel de protocolo 4 = MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, mqttKeepAlive)
+	body, err := mqttString(body, options.ClientID)
+	if err != nil {
+		return fmt.Errorf("invalid client ID: %w", err)
+	}
+	if flags&0x80 != 0 {
+		if body, err = mqttString(body, options.Username); err != nil {
+			return fmt.Errorf("invalid user name: %w", err)
+		}
+	}
+	if flags&0x40 != 0 {
+		if body, err = mqttString(body, options.Password); err != nil {
+			return fmt.Errorf("invalid password: %w", err)
+		}
+	}
+	if err := c.write(mqttConnect, body); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+
+	packetType, payload, err := c.read()
+	if err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if packetType != mqttConnack || len(payload) != 2 {
+		return fmt.Errorf("unexpected packet 0x%02x while waiting for connack", packetType)
+	}
+	if code := payload[1]; code != 0 {
+		if reason, ok := mqttConnackErrors[code]; ok {
+			return fmt.Errorf("broker refused connection: %s", reason)
+		}
+		return fmt.Errorf("broker refused connection with code %d", code)
+	}
+	return nil
+}
+
+// Publish publica un mensaje; con QoS 1 espera el PUBACK del broker
+func (c *mqttClient) Publish(message mqttMessage, qos byte) error {
+	header := mqttPublish | qos<<1
+	if message.Retain {
+		header |= 0x01
+	}
+
+	body, err := mqttString(nil, message.Topic)
+	if err != nil {
+		return fmt.Errorf("invalid topic: %w", err)
+	}
+	var id uint16
+	if qos > 0 {
+		c.packetID++
+		if c.packetID == 0 {
+			c.packetID = 1
+		}
+		id = c.packetID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, message.Payload...)
+
+	if err := c.write(header, body); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", message.Topic, err)
+	}
+	if qos == 0 {
+		return nil
+	}
+
+	for {
+		packetType, payload, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read puback for %s: %w", message.Topic, err)
+		}
+		// Se ignoran otros paquetes (ej: PINGRESP) hasta recibir el PUBACK
+		if packetType == mqttPuback && len(payload) == 2 && binary.BigEndian.Uint16(payload) == id {
+			return nil
+		}
+	}
+}
+
+// Close envía DISCONNECT y cierra la conexión
+func (c *mqttClient) Close() error {
+	writeErr := c.write(mqttDisconnect, nil)
+	closeErr := c.conn.Close()
+	return errors.Join(writeErr, closeErr)
+}
+
+// write envía un paquete con su cabecera fija
+func (c *mqttClient) write(header byte, body []byte) error {
+	if len(body) > mqttMaxRemainingLength {
+		return fmt.Errorf("packet too large (%d bytes)", len(body))
+	}
+	packet := append([]byte{header}, mqttRemainingLength(len(body))...)
+	packet = append(packet, body...)
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// read lee un paquete y devuelve su tipo (sin flags) y su contenido
+func (c *mqttClient) read() (byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("malformed remaining length")
+		}
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	return header & 0xF0, payload, nil
+}
+
+// mqttRemainingLength codifica la longitud restante en 1-4 bytes
+func mqttRemainingLength(n int) []byte {
+	var encoded []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		encoded = append(encoded, b)
+		if n == 0 {
+			return encoded
+		}
+	}
+}
+
+// mqttMaxStringLength longitud máxima de un string MQTT (prefijo de 16 bits)
+const mqttMaxStringLength = 65535
+
+// mqttString añade un string UTF-8 precedido de su longitud; falla si no cabe
+// en el prefijo de 16 bits en lugar de truncar la longitud
+func mqttString(buf []byte, s string) ([]byte, error) {
+	if len(s) > mqttMaxStringLength {
+		return buf, fmt.Errorf("string too long (%d bytes, max %d)", len(s), mqttMaxStringLength)
+	}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...), nil
+}
+
+// randomClientID genera un identificador de cliente único por conexión
+func randomClientID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("dic-%x", time.Now().UnixNano())
+	}
+	return "dic-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,313 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// mqttConnectPacket campos de un CONNECT recibido por el broker falso
+type mqttConnectPacket struct {
+	Protocol string
+	Level    byte
+	Flags    byte
+	ClientID string
+	Username string
+	Password string
+}
+
+// mqttPublishPacket PUBLISH recibido por el broker falso
+type mqttPublishPacket struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retain   bool
+	PacketID uint16
+}
+
+// fakeBroker broker MQTT mínimo sobre un net.Listener que acepta una
+// conexión, responde CONNACK y PUBACK y registra lo recibido
+type fakeBroker struct {
+	listener     net.Listener
+	connect      mqttConnectPacket
+	publishes    []mqttPublishPacket
+	disconnected bool
+	done         chan error
+}
+
+func startFakeBroker(t *testing.T, tlsConfig *tls.Config) *fakeBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &fakeBroker{listener: listener, done: make(chan error, 1)}
+	go func() { broker.done <- broker.serve() }()
+	return broker
+}
+
+// wait espera a que el cliente cierre la conexión
+func (b *fakeBroker) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case err := <-b.done:
+		if err != nil {
+			t.Fatalf("broker: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the mqtt client")
+	}
+}
+
+func (b *fakeBroker) serve() error {
+	conn, err := b.listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// El broker reutiliza el codificador del cliente
+	server := &mqttClient{conn: conn, reader: bufio.NewReader(conn)}
+	for {
+		first, err := server.reader.Peek(1)
+		if err != nil {
+			return nil
+		}
+		flags := first[0] & 0x0F
+		packetType, body, err := server.read()
+		if err != nil {
+			return err
+		}
+
+		switch packetType {
+		case mqttConnect:
+			b.connect = parseConnect(body)
+			if err := server.write(mqttConnack, []byte{0, 0}); err != nil {
+				return err
+			}
+		case mqttPublish:
+			publish := parsePublish(body, flags)
+			b.publishes = append(b.publishes, publish)
+			if publish.QoS == 1 {
+				if err := server.write(mqttPuback, binary.BigEndian.AppendUint16(nil, publish.PacketID)); err != nil {
+					return err
+				}
+			}
+		case mqttDisconnect:
+			b.disconnected = true
+			return nil
+		}
+	}
+}
+
+// readMQTTString lee un string con prefijo de longitud
+func readMQTTString(body []byte) (string, []byte) {
+	n := int(binary.BigEndian.Uint16(body))
+	return string(body[2 : 2+n]), body[2+n:]
+}
+
+func parseConnect(body []byte) mqttConnectPacket {
+	var packet mqttConnectPacket
+	packet.Protocol, body = readMQTTString(body)
+	packet.Level, packet.Flags = body[0], body[1]
+	body = body[4:] // nivel, flags y keep alive
+	packet.ClientID, body = readMQTTString(body)
+	if packet.Flags&0x80 != 0 {
+		packet.Username, body = readMQTTString(body)
+	}
+	if packet.Flags&0x40 != 0 {
+		packet.Password, _ = readMQTTString(body)
+	}
+	return packet
+}
+
+func parsePublish(body []byte, flags byte) mqttPublishPacket {
+	packet := mqttPublishPacket{QoS: flags >> 1 & 0x03, Retain: flags&0x01 != 0}
+	packet.Topic, body = readMQTTString(body)
+	if packet.QoS > 0 {
+		packet.PacketID = binary.BigEndian.Uint16(body)
+		body = body[2:]
+	}
+	packet.Payload = body
+	return packet
+}
+
+func mqttTestData() *model.NotificationData {
+	web := model.UpdateInfo{
+		Container: model.Container{
+			Name: "web", ImageName: "nginx:1.25.3",
+			Labels: map[string]string{"org.opencontainers.image.source": "https://github.com/nginx/nginx"},
+		},
+		CurrentVersion: "1.25.3",
+		LatestVersion:  "1.25.4",
+	}
+	return &model.NotificationData{
+		Hostname: "host",
+		Report:   &model.CheckReport{Hostname: "host", Total: 1, Available: []model.UpdateInfo{web}},
+	}
+}
+
+func TestMQTTNotifyQoS1WithDiscovery(t *testing.T) {
+	broker := startFakeBroker(t, nil)
+	notifier, err := NewMQTTNotifier(MQTTOptions{
+		BrokerURL: "mqtt://" + broker.listener.Addr().String(),
+		ClientID:  "checker",
+		Username:  "user",
+		Password:  "p@ss:word",
+		QoS:       1,
+		Discovery: true,
+	})
+	if err != nil {
+		t.Fatalf("NewMQTTNotifier: %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), mqttTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	broker.wait(t)
+
+	want := mqttConnectPacket{Protocol: "MQTT", Level: 4, Flags: 0xC2, ClientID: "checker", Username: "user", Password: "p@ss:word"}
+	if broker.connect != want {
+		t.Errorf("connect = %+v, want %+v", broker.connect, want)
+	}
+	if !broker.disconnected {
+		t.Error("client did not send DISCONNECT")
+	}
+
+	topics := make(map[string][]byte)
+	for i, publish := range broker.publishes {
+		if publish.QoS != 1 || !publish.Retain || publish.PacketID != uint16(i+1) {
+			t.Errorf("publish %s: qos=%d retain=%v id=%d", publish.Topic, publish.QoS, publish.Retain, publish.PacketID)
+		}
+		topics[publish.Topic] = publish.Payload
+	}
+
+	var discovery mqttDiscovery
+	if err := json.Unmarshal(topics["homeassistant/update/host/web/config"], &discovery); err != nil {
+		t.Fatalf("discovery config: %v (topics %v)", err, broker.publishes)
+	}
+	if discovery.StateTopic != "docker-image-checker/host/web/state" || discovery.UniqueID != "docker_image_checker_host_web" {
+		t.Errorf("discovery = %+v", discovery)
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal(topics[discovery.StateTopic], &state); err != nil {
+		t.Fatalf("state: %v", err)
+	}
+	for key, want := range map[string]string{
+		"installed_version": "1.25.3",
+		"latest_version":    "1.25.4",
+		"release_url":       "https://github.com/nginx/nginx",
+	} {
+		if state[key] != want {
+			t.Errorf("state %s = %v, want %q", key, state[key], want)
+		}
+	}
+	if _, ok := topics["docker-image-checker/host/summary"]; !ok {
+		t.Error("summary not published")
+	}
+}
+
+func TestMQTTNotifyTLS(t *testing.T) {
+	serverTLS, caFile := testTLSConfig(t)
+	broker := startFakeBroker(t, serverTLS)
+	notifier, err := NewMQTTNotifier(MQTTOptions{
+		BrokerURL: "mqtts://" + broker.listener.Addr().String(),
+		CAFile:    caFile,
+	})
+	if err != nil {
+		t.Fatalf("NewMQTTNotifier: %v", err)
+	}
+
+	if err := notifier.Notify(context.Background(), mqttTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	broker.wait(t)
+
+	if broker.connect.Flags != 0x02 || !strings.HasPrefix(broker.connect.ClientID, "dic-") {
+		t.Errorf("connect = %+v", broker.connect)
+	}
+	if len(broker.publishes) != 2 || broker.publishes[0].QoS != 0 {
+		t.Errorf("publishes = %+v", broker.publishes)
+	}
+}
+
+func TestMQTTStringTooLong(t *testing.T) {
+	if _, err := mqttString(nil, strings.Repeat("a", 65535)); err != nil {
+		t.Errorf("65535 bytes: %v", err)
+	}
+	if _, err := mqttString(nil, strings.Repeat("a", 65536)); err == nil {
+		t.Error("expected an error for 65536 bytes")
+	}
+
+	broker := startFakeBroker(t, nil)
+	notifier, err := NewMQTTNotifier(MQTTOptions{
+		BrokerURL:   "mqtt://" + broker.listener.Addr().String(),
+		TopicPrefix: strings.Repeat("a", 70000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), mqttTestData()); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Errorf("Notify error = %v, want a topic length error", err)
+	}
+	broker.wait(t)
+	if len(broker.publishes) != 0 {
+		t.Errorf("published %d messages with a truncated topic length", len(broker.publishes))
+	}
+}
+
+// testTLSConfig genera un certificado autofirmado para 127.0.0.1 y devuelve
+// la configuración del servidor y la CA en un fichero PEM
+func testTLSConfig(t *testing.T) (*tls.Config, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test broker"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, caFile
+}
@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// NtfyOptions configuración del notificador de ntfy
+type NtfyOptions struct {
+	// TopicURL URL completa del topic (ej: https://ntfy.sh/mis-contenedores)
+	TopicURL string
+	// Token de acceso opcional para servidores con autenticación
+	Token string
+	// Priority fija la prioridad (1-5); si es 0 se deriva del reporte
+	Priority     int
+	Tags         []string
+	Click        string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// NtfyNotifier implementa Observer para notificaciones push via ntfy
+type NtfyNotifier struct {
+	options   NtfyOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// NewNtfyNotifier crea un nuevo notificador de ntfy
+func NewNtfyNotifier(options NtfyOptions) (*NtfyNotifier, error) {
+	if options.TopicURL == "" {
+		return nil, fmt.Errorf("ntfy topic URL is required")
+	}
+	if options.Priority < 0 || options.Priority > 5 {
+		return nil, fmt.Errorf("ntfy priority must be 0 (auto) or 1-5, got %d", options.Priority)
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "ntfy",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ntfy template: %w", err)
+	}
+
+	return &NtfyNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (nn *NtfyNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	message, err := nn.templates.Render(data)
+	if err != nil {
+		return fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, nn.options.TopicURL, strings.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to create ntfy request: %w", err)
+	}
+
+	req.Header.Set("Title", messageTitle(data))
+	req.Header.Set("Priority", strconv.Itoa(nn.priority(data)))
+	if len(nn.options.Tags) > 0 {
+		req.Header.Set("Tags", strings.Join(nn.options.Tags, ","))
+	}
+	if nn.options.Click != "" {
+		req.Header.Set("Click", nn.options.Click)
+	}
+	if nn.options.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+nn.options.Token)
+	}
+
+	resp, err := nn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send ntfy message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("ntfy", resp)
+}
+
+// Preview implementa la interfaz Previewer; ntfy recibe el mensaje tal cual
+// como cuerpo y el resto de campos como cabeceras
+func (nn *NtfyNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, err := nn.templates.Render(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+	return &Preview{Message: message, Payloads: [][]byte{[]byte(message)}}, nil
+}
+
+// priority devuelve la prioridad configurada o la derivada del reporte
+func (nn *NtfyNotifier) priority(data *model.NotificationData) int {
+	if nn.options.Priority > 0 {
+		return nn.options.Priority
+	}
+
+	// Escala de ntfy: 1 (min) - 3 (default) - 5 (max)
+	switch SeverityOf(data) {
+	case SeverityFailure:
+		return 5
+	case SeverityMajor:
+		return 4
+	case SeverityUpdates:
+		return 3
+	default:
+		return 2
+	}
+}
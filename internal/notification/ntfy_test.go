@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// severityData datos de notificación con las actualizaciones y fallos indicados
+func severityData(updates []model.UpdateInfo, failed bool) *model.NotificationData {
+	report := &model.CheckReport{Hostname: "host", Available: updates}
+	if failed {
+		report.Failed = []model.UpdateInfo{{
+			Container: model.Container{Name: "db", ImageName: "postgres:16"},
+			Error:     errors.New("registry unreachable"),
+		}}
+	}
+	report.Total = len(report.Available) + len(report.Failed)
+	return &model.NotificationData{Hostname: "host", Report: report}
+}
+
+// versionUpdate actualización de web entre las versiones indicadas
+func versionUpdate(current, latest string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container:      model.Container{Name: "web", ImageName: "nginx:" + current},
+		CurrentVersion: current,
+		LatestVersion:  latest,
+	}
+}
+
+func TestNtfyPriority(t *testing.T) {
+	minor := []model.UpdateInfo{versionUpdate("1.25.3", "1.26.0")}
+	major := []model.UpdateInfo{versionUpdate("1.25.3", "2.0.0")}
+
+	tests := []struct {
+		name       string
+		configured int
+		data       *model.NotificationData
+		want       int
+	}{
+		{name: "nothing to report", data: severityData(nil, false), want: 2},
+		{name: "no report", data: &model.NotificationData{Hostname: "host"}, want: 2},
+		{name: "updates", data: severityData(minor, false), want: 3},
+		{name: "major update", data: severityData(major, false), want: 4},
+		{name: "failure", data: severityData(minor, true), want: 5},
+		{name: "failure wins over major", data: severityData(major, true), want: 5},
+		{name: "configured overrides derived", configured: 1, data: severityData(major, true), want: 1},
+		{name: "configured max", configured: 5, data: severityData(nil, false), want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, err := NewNtfyNotifier(NtfyOptions{TopicURL: "https://ntfy.sh/updates", Priority: tt.configured})
+			if err != nil {
+				t.Fatalf("NewNtfyNotifier: %v", err)
+			}
+			assertEqual(t, notifier.priority(tt.data), tt.want)
+		})
+	}
+}
+
+func TestNtfyPriorityValidation(t *testing.T) {
+	for _, priority := range []int{-1, 6} {
+		_, err := NewNtfyNotifier(NtfyOptions{TopicURL: "https://ntfy.sh/updates", Priority: priority})
+		if err == nil || !strings.Contains(err.Error(), "0 (auto) or 1-5") {
+			t.Errorf("priority %d: error = %v, want a range error", priority, err)
+		}
+	}
+	for priority := 0; priority <= 5; priority++ {
+		if _, err := NewNtfyNotifier(NtfyOptions{TopicURL: "https://ntfy.sh/updates", Priority: priority}); err != nil {
+			t.Errorf("priority %d: unexpected error %v", priority, err)
+		}
+	}
+}
+
+func TestNtfyNotifyHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		headers <- r.Header
+	}))
+	defer server.Close()
+
+	notifier, err := NewNtfyNotifier(NtfyOptions{TopicURL: server.URL + "/updates", Tags: []string{"docker", "whale"}})
+	if err != nil {
+		t.Fatalf("NewNtfyNotifier: %v", err)
+	}
+	data := severityData([]model.UpdateInfo{versionUpdate("1.25.3", "2.0.0")}, false)
+	if err := notifier.Notify(context.Background(), data); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := <-headers
+	assertEqual(t, got.Get("Priority"), "4")
+	assertEqual(t, got.Get("Tags"), "docker,whale")
+}
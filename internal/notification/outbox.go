@@ -0,0 +1,317 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/metrics"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	defaultOutboxMaxAge     = 72 * time.Hour
+	defaultOutboxMinBackoff = 30 * time.Second
+	defaultOutboxMaxBackoff = time.Hour
+)
+
+// OutboxOptions configuración del outbox persistente
+type OutboxOptions struct {
+	// Dir directorio donde se guarda cada notificación pendiente
+	Dir string
+	// MaxAge tiempo tras el que una notificación no entregada se descarta
+	MaxAge time.Duration
+	// MinBackoff y MaxBackoff limitan la espera exponencial entre reintentos
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// OutboxStats contadores de entregas desde que arrancó el proceso
+type OutboxStats struct {
+	Delivered int
+	Retried   int
+	Expired   int
+	Pending   int
+}
+
+// outboxEntry notificación pendiente para un notificador concreto
+type outboxEntry struct {
+	ID          string                  `json:"id"`
+	Notifier    string                  `json:"notifier"`
+	Data        *model.NotificationData `json:"data"`
+	CreatedAt   time.Time               `json:"created_at"`
+	Attempts    int                     `json:"attempts"`
+	NextAttempt time.Time               `json:"next_attempt"`
+	LastError   string                  `json:"last_error,omitempty"`
+}
+
+// Outbox guarda en disco las notificaciones hasta que se entregan o caducan,
+// de forma que sobreviven a caídas del servicio y a reinicios del daemon
+type Outbox struct {
+	options OutboxOptions
+
+	mu    sync.Mutex
+	stats OutboxStats
+	// inFlight entradas que se están entregando; el lock no se mantiene
+	// durante las entregas, así que otro Deliver debe saltárselas
+	inFlight map[string]bool
+}
+
+// NewOutbox crea el outbox y su directorio
+func NewOutbox(options OutboxOptions) (*Outbox, error) {
+	if options.Dir == "" {
+		return nil, fmt.Errorf("outbox directory is required")
+	}
+	if options.MaxAge <= 0 {
+		options.MaxAge = defaultOutboxMaxAge
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaultOutboxMinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = max(defaultOutboxMaxBackoff, options.MinBackoff)
+	}
+
+	if err := os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	outbox := &Outbox{options: options, inFlight: make(map[string]bool)}
+
+	// Contar las notificaciones que quedaron pendientes antes de un reinicio
+	outbox.mu.Lock()
+	defer outbox.mu.Unlock()
+	if _, err := outbox.load(); err != nil {
+		return nil, err
+	}
+	outbox.recordStats()
+	return outbox, nil
+}
+
+// RetryInterval cada cuánto conviene revisar las notificaciones pendientes
+func (o *Outbox) RetryInterval() time.Duration {
+	return o.options.MinBackoff
+}
+
+// Stats devuelve los contadores de entregas
+func (o *Outbox) Stats() OutboxStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stats
+}
+
+// Enqueue guarda una notificación para un notificador
+func (o *Outbox) Enqueue(notifier string, data *model.NotificationData) error {
+	return o.enqueue(notifier, data, time.Now())
+}
+
+// enqueue guarda una notificación con la hora indicada
+func (o *Outbox) enqueue(notifier string, data *model.NotificationData, now time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry := &outboxEntry{
+		ID:          newOutboxID(now),
+		Notifier:    notifier,
+		Data:        data,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+
+	if err := o.write(entry); err != nil {
+		return fmt.Errorf("failed to enqueue notification for %s: %w", notifier, err)
+	}
+	o.stats.Pending++
+	o.recordStats()
+	return nil
+}
+
+// errUnknownNotifier indica que el notificador de una entrada ya no está configurado
+var errUnknownNotifier = errors.New("unknown notifier")
+
+// DeliverFunc entrega una notificación al notificador con el nombre indicado
+type DeliverFunc func(ctx context.Context, notifier string, data *model.NotificationData) error
+
+// Deliver intenta entregar en paralelo las notificaciones pendientes cuyo
+// reintento ha vencido y devuelve los errores de entrega de este intento.
+// Las entregas se hacen sin bloquear el outbox, que sigue aceptando
+// notificaciones mientras tanto.
+func (o *Outbox) Deliver(ctx context.Context, deliver DeliverFunc) error {
+	return o.deliver(ctx, deliver, time.Now())
+}
+
+// deliver entrega las notificaciones vencidas a la hora indicada
+func (o *Outbox) deliver(ctx context.Context, deliver DeliverFunc, now time.Time) error {
+	due, err := o.takeDue(now)
+	if err != nil {
+		return err
+	}
+
+	results := make([]error, len(due))
+	var wg sync.WaitGroup
+	for i, entry := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = deliver(ctx, entry.Notifier, entry.Data)
+		}()
+	}
+	wg.Wait()
+
+	return o.complete(due, results, now)
+}
+
+// takeDue descarta las entradas caducadas y reserva las que deben
+// reintentarse, marcándolas como en curso
+func (o *Outbox) takeDue(now time.Time) ([]*outboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	defer o.recordStats()
+
+	entries, err := o.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*outboxEntry
+	for _, entry := range entries {
+		switch {
+		case o.inFlight[entry.ID]:
+		case now.Sub(entry.CreatedAt) > o.options.MaxAge:
+			log.Printf("Outbox: notification %s for %s expired after %d attempts: %s", entry.ID, entry.Notifier, entry.Attempts, entry.LastError)
+			o.stats.Expired++
+			o.remove(entry)
+		case !now.Before(entry.NextAttempt):
+			o.inFlight[entry.ID] = true
+			due = append(due, entry)
+		}
+	}
+	return due, nil
+}
+
+// complete guarda el resultado de las entregas: borra las entregadas y
+// programa el siguiente reintento de las que han fallado
+func (o *Outbox) complete(due []*outboxEntry, results []error, now time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	defer o.recordStats()
+
+	var errs []error
+	for i, entry := range due {
+		delete(o.inFlight, entry.ID)
+
+		err := results[i]
+		switch {
+		case errors.Is(err, errUnknownNotifier):
+			log.Printf("Outbox: dropping notification %s for unknown notifier %s", entry.ID, entry.Notifier)
+			o.remove(entry)
+		case err != nil:
+			entry.Attempts++
+			entry.LastError = err.Error()
+			entry.NextAttempt = now.Add(o.backoff(entry.Attempts))
+			log.Printf("Outbox: delivery of %s failed (attempt %d, next retry %s): %v",
+				entry.ID, entry.Attempts, entry.NextAttempt.Format(time.RFC3339), err)
+			o.stats.Retried++
+			if err := o.write(entry); err != nil {
+				log.Printf("Outbox: failed to update %s: %v", entry.ID, err)
+			}
+			errs = append(errs, err)
+		default:
+			entry.Attempts++
+			if entry.Attempts > 1 {
+				log.Printf("Outbox: notification %s delivered to %s after %d attempts", entry.ID, entry.Notifier, entry.Attempts)
+			}
+			o.stats.Delivered++
+			o.remove(entry)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recordStats publica los contadores en las métricas del outbox
+func (o *Outbox) recordStats() {
+	metrics.OutboxPending.Set(float64(o.stats.Pending))
+	metrics.OutboxExpired.Set(float64(o.stats.Expired))
+}
+
+// backoff espera exponencial tras el intento n
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.options.MinBackoff
+	for i := 1; i < attempts && delay < o.options.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, o.options.MaxBackoff)
+}
+
+// load lee las notificaciones pendientes ordenadas por antigüedad
+func (o *Outbox) load() ([]*outboxEntry, error) {
+	files, err := filepath.Glob(filepath.Join(o.options.Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+	sort.Strings(files)
+
+	entries := make([]*outboxEntry, 0, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Outbox: failed to read %s: %v", file, err)
+			continue
+		}
+		var entry outboxEntry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			log.Printf("Outbox: discarding corrupt entry %s: %v", file, err)
+			os.Remove(file)
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+
+	o.stats.Pending = len(entries)
+	return entries, nil
+}
+
+// write guarda una entrada de forma atómica
+func (o *Outbox) write(entry *outboxEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := o.path(entry)
+	if err := os.WriteFile(path+".tmp", content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// remove borra una entrada entregada o descartada
+func (o *Outbox) remove(entry *outboxEntry) {
+	if err := os.Remove(o.path(entry)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Outbox: failed to remove %s: %v", entry.ID, err)
+	}
+	o.stats.Pending--
+}
+
+// path ruta del fichero de una entrada
+func (o *Outbox) path(entry *outboxEntry) string {
+	return filepath.Join(o.options.Dir, entry.ID+".json")
+}
+
+// newOutboxID genera un ID ordenable por fecha de creación
+func newOutboxID(now time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return strings.ReplaceAll(now.UTC().Format("20060102T150405.000000000"), ".", "") + "-" + hex.EncodeToString(suffix)
+}
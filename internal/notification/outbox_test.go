@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pablopin/docker-image-checker/internal/metrics"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func newTestOutbox(t *testing.T, dir string) *Outbox {
+	t.Helper()
+	outbox, err := NewOutbox(OutboxOptions{
+		Dir:        dir,
+		MaxAge:     time.Hour,
+		MinBackoff: time.Minute,
+		MaxBackoff: 4 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	return outbox
+}
+
+// countingDeliver cuenta las entregas y falla mientras fail sea true
+func countingDeliver(calls *atomic.Int32, fail *atomic.Bool) DeliverFunc {
+	return func(ctx context.Context, notifier string, data *model.NotificationData) error {
+		calls.Add(1)
+		if fail.Load() {
+			return errors.New("service unavailable")
+		}
+		return nil
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	outbox := newTestOutbox(t, t.TempDir())
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := outbox.enqueue("ops", &model.NotificationData{Hostname: "host"}, start); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	var fail atomic.Bool
+	fail.Store(true)
+	deliver := countingDeliver(&calls, &fail)
+
+	// Cada fallo duplica la espera hasta MaxBackoff: 1m, 2m, 4m, 4m
+	now := start
+	for _, wait := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		if err := outbox.deliver(context.Background(), deliver, now); err == nil {
+			t.Fatal("expected a delivery error")
+		}
+		before := calls.Load()
+		if err := outbox.deliver(context.Background(), deliver, now.Add(wait-time.Second)); err != nil {
+			t.Fatalf("retry before backoff: %v", err)
+		}
+		if calls.Load() != before {
+			t.Fatalf("retried %v after a failure, before the %v backoff", wait-time.Second, wait)
+		}
+		now = now.Add(wait)
+	}
+
+	fail.Store(false)
+	if err := outbox.deliver(context.Background(), deliver, now); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	stats := outbox.Stats()
+	if calls.Load() != 5 || stats.Delivered != 1 || stats.Retried != 4 || stats.Pending != 0 {
+		t.Errorf("calls = %d, stats = %+v", calls.Load(), stats)
+	}
+}
+
+func TestOutboxExpiry(t *testing.T) {
+	outbox := newTestOutbox(t, t.TempDir())
+	start := time.Now()
+	if err := outbox.enqueue("ops", &model.NotificationData{}, start); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	var fail atomic.Bool
+	if err := outbox.deliver(context.Background(), countingDeliver(&calls, &fail), start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("expired notification was delivered")
+	}
+	stats := outbox.Stats()
+	if stats.Expired != 1 || stats.Pending != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got := testutil.ToFloat64(metrics.OutboxExpired); got != 1 {
+		t.Errorf("outbox_expired = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.OutboxPending); got != 0 {
+		t.Errorf("outbox_pending = %v, want 0", got)
+	}
+}
+
+func TestOutboxReloadAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	first := newTestOutbox(t, dir)
+	if err := first.enqueue("ops", &model.NotificationData{Hostname: "host-1"}, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.enqueue("dev", &model.NotificationData{Hostname: "host-2"}, start); err != nil {
+		t.Fatal(err)
+	}
+
+	// Un proceso nuevo recupera lo pendiente del directorio
+	second := newTestOutbox(t, dir)
+	if stats := second.Stats(); stats.Pending != 2 {
+		t.Fatalf("pending after reload = %d, want 2", stats.Pending)
+	}
+	if got := testutil.ToFloat64(metrics.OutboxPending); got != 2 {
+		t.Errorf("outbox_pending = %v, want 2", got)
+	}
+
+	delivered := make(map[string]string)
+	err := second.deliver(context.Background(), func(ctx context.Context, notifier string, data *model.NotificationData) error {
+		if notifier == "dev" {
+			return errUnknownNotifier
+		}
+		delivered[notifier] = data.Hostname
+		return nil
+	}, start)
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if len(delivered) != 1 || delivered["ops"] != "host-1" {
+		t.Errorf("delivered = %v", delivered)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 0 || second.Stats().Pending != 0 {
+		t.Errorf("outbox not empty: %v, stats %+v", files, second.Stats())
+	}
+}
+
+func TestOutboxDeliverDoesNotHoldLock(t *testing.T) {
+	outbox := newTestOutbox(t, t.TempDir())
+	if err := outbox.Enqueue("ops", &model.NotificationData{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- outbox.Deliver(context.Background(), func(ctx context.Context, notifier string, data *model.NotificationData) error {
+			calls.Add(1)
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// Durante la entrega se puede encolar y otro Deliver no repite la entrada en curso
+	enqueued := make(chan error)
+	go func() { enqueued <- outbox.Enqueue("ops", &model.NotificationData{}) }()
+	select {
+	case err := <-enqueued:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Enqueue blocked by a delivery in progress")
+	}
+	var otherCalls atomic.Int32
+	var fail atomic.Bool
+	if err := outbox.Deliver(context.Background(), countingDeliver(&otherCalls, &fail)); err != nil {
+		t.Fatal(err)
+	}
+	if otherCalls.Load() != 1 {
+		t.Errorf("concurrent Deliver made %d deliveries, want only the new entry", otherCalls.Load())
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("first Deliver made %d deliveries, want 1", calls.Load())
+	}
+	if entries, _ := os.ReadDir(outbox.options.Dir); len(entries) != 0 {
+		t.Errorf("%d entries left in the outbox", len(entries))
+	}
+}
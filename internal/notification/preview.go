@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// Preview resultado de preparar una notificación sin enviarla
+type Preview struct {
+	// Message texto generado por la plantilla
+	Message string
+	// Payloads cuerpos de las peticiones que se enviarían, en orden
+	Payloads [][]byte
+}
+
+// Previewer lo implementan los notificadores que pueden mostrar exactamente
+// lo que enviarían sin llegar a enviarlo
+type Previewer interface {
+	Preview(data *model.NotificationData) (*Preview, error)
+}
+
+// PreviewOf genera la vista previa de un observer, también si está envuelto
+// con WithName
+func PreviewOf(observer Observer, data *model.NotificationData) (*Preview, error) {
+	if named, ok := observer.(*namedObserver); ok {
+		observer = named.Observer
+	}
+
+	previewer, ok := observer.(Previewer)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support previews", displayName(observer))
+	}
+	return previewer.Preview(data)
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// Match criterios de una regla de enrutado. Cada criterio vacío se ignora;
+// los criterios indicados deben cumplirse todos y, dentro de una lista,
+// basta con que coincida un valor. Nombres, imágenes, proyectos y valores de
+// etiquetas admiten comodines (* y ?).
+type Match struct {
+	Containers      []string
+	Images          []string
+	Labels          map[string]string
+	ComposeProjects []string
+	Statuses        []model.UpdateStatus
+	Bumps           []model.BumpLevel
+}
+
+// Route envía a los notificadores indicados solo los contenedores que cumplen Match
+type Route struct {
+	Name      string
+	Notifiers []string
+	Match     Match
+}
+
+// Router filtra los datos de notificación para cada notificador según las rutas
+type Router struct {
+	routes []compiledRoute
+}
+
+// compiledRoute ruta con los comodines ya compilados
+type compiledRoute struct {
+	Route
+	containers []*regexp.Regexp
+	images     []*regexp.Regexp
+	labels     map[string]*regexp.Regexp
+	projects   []*regexp.Regexp
+}
+
+// NewRouter valida y compila las reglas de enrutado
+func NewRouter(routes []Route) (*Router, error) {
+	router := &Router{}
+
+	for i, route := range routes {
+		if len(route.Notifiers) == 0 {
+			return nil, fmt.Errorf("route %d (%s) has no notifiers", i, route.Name)
+		}
+
+		compiled := compiledRoute{
+			Route:      route,
+			containers: compileGlobs(route.Match.Containers),
+			images:     compileGlobs(route.Match.Images),
+			projects:   compileGlobs(route.Match.ComposeProjects),
+			labels:     make(map[string]*regexp.Regexp),
+		}
+		for key, value := range route.Match.Labels {
+			compiled.labels[key] = compileGlob(value)
+		}
+
+		router.routes = append(router.routes, compiled)
+	}
+
+	return router, nil
+}
+
+// Notifiers devuelve los nombres de notificador referenciados por alguna ruta
+func (r *Router) Notifiers() []string {
+	var names []string
+	for _, route := range r.routes {
+		names = append(names, route.Notifiers...)
+	}
+	return names
+}
+
+// Filter devuelve los datos que corresponden al notificador indicado y si hay
+// que notificarle. Los notificadores sin rutas reciben el reporte completo.
+func (r *Router) Filter(notifier string, data *model.NotificationData) (*model.NotificationData, bool) {
+	var routes []compiledRoute
+	for _, route := range r.routes {
+		for _, name := range route.Notifiers {
+			if name == notifier {
+				routes = append(routes, route)
+				break
+			}
+		}
+	}
+	if len(routes) == 0 || data.Report == nil {
+		return data, true
+	}
+
+	matches := func(info model.UpdateInfo) bool {
+		for _, route := range routes {
+			if route.matches(info) {
+				return true
+			}
+		}
+		return false
+	}
+
+	report := &model.CheckReport{
+		Hostname:  data.Report.Hostname,
+		Timestamp: data.Report.Timestamp,
+		Available: filterUpdates(data.Report.Available, matches),
+		Failed:    filterUpdates(data.Report.Failed, matches),
+		UpToDate:  filterUpdates(data.Report.UpToDate, matches),
+	}
+	report.Total = len(report.Available) + len(report.Failed) + len(report.UpToDate)
+
+	filtered := &model.NotificationData{
+		Report:   report,
+		Hostname: data.Hostname,
+	}
+
+	// Con seguimiento de estado solo se notifica si hay cambios que le afecten
+	if data.Changes != nil {
+		filtered.Changes = &model.ChangeSet{
+			NewUpdates:  filterUpdates(data.Changes.NewUpdates, matches),
+			NewFailures: filterUpdates(data.Changes.NewFailures, matches),
+			Resolved:    filterUpdates(data.Changes.Resolved, matches),
+			Reminders:   filterUpdates(data.Changes.Reminders, matches),
+			Recovered:   filterUpdates(data.Changes.Recovered, matches),
+			Applied:     filterUpdates(data.Changes.Applied, matches),
+			Removed:     filterUpdates(data.Changes.Removed, matches),
+		}
+		return filtered, !filtered.Changes.IsEmpty()
+	}
+
+	return filtered, len(report.Available) > 0 || len(report.Failed) > 0
+}
+
+// matches indica si un contenedor cumple todos los criterios de la ruta
+func (cr compiledRoute) matches(info model.UpdateInfo) bool {
+	if len(cr.containers) > 0 && !matchAny(cr.containers, info.Container.Name) {
+		return false
+	}
+	if len(cr.images) > 0 && !matchAny(cr.images, info.Container.ImageName) {
+		return false
+	}
+	if len(cr.projects) > 0 && !matchAny(cr.projects, info.Container.ComposeProject()) {
+		return false
+	}
+	for key, pattern := range cr.labels {
+		value, ok := info.Container.Labels[key]
+		if !ok || !pattern.MatchString(value) {
+			return false
+		}
+	}
+	if len(cr.Match.Statuses) > 0 && !slices.Contains(cr.Match.Statuses, info.Status()) {
+		return false
+	}
+	if len(cr.Match.Bumps) > 0 && (info.Status() != model.StatusAvailable || !slices.Contains(cr.Match.Bumps, info.Bump())) {
+		return false
+	}
+	return true
+}
+
+// filterUpdates devuelve los elementos que cumplen el predicado
+func filterUpdates(updates []model.UpdateInfo, keep func(model.UpdateInfo) bool) []model.UpdateInfo {
+	result := make([]model.UpdateInfo, 0)
+	for _, update := range updates {
+		if keep(update) {
+			result = append(result, update)
+		}
+	}
+	return result
+}
+
+// compileGlobs compila una lista de comodines
+func compileGlobs(globs []string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		result = append(result, compileGlob(glob))
+	}
+	return result
+}
+
+// compileGlob convierte un comodín en una expresión regular anclada donde
+// * coincide con cualquier secuencia (incluida "/") y ? con un carácter
+func compileGlob(glob string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// matchAny indica si el valor coincide con alguno de los patrones
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
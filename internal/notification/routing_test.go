@@ -0,0 +1,274 @@
+package notification
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// routedContainer contenedor de prueba con imagen, etiquetas y versiones
+func routedContainer(name, image string, labels map[string]string, current, latest string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container:      model.Container{Name: name, ImageName: image, Labels: labels},
+		CurrentVersion: current,
+		LatestVersion:  latest,
+		IsUpToDate:     current == latest,
+	}
+}
+
+// routingTestData reporte con una actualización menor, una mayor, un fallo y
+// un contenedor al día
+func routingTestData() *model.NotificationData {
+	web := routedContainer("web-1", "registry.example.com/app/web:1.2.0",
+		map[string]string{"com.docker.compose.project": "shop", "team": "frontend"}, "1.2.0", "1.3.0")
+	api := routedContainer("api", "ghcr.io/acme/api:v2.0.0",
+		map[string]string{"team": "backend"}, "v2.0.0", "v3.0.0")
+	db := routedContainer("db", "postgres:16",
+		map[string]string{"com.docker.compose.project": "shop"}, "16", "")
+	db.Error = errors.New("registry unreachable")
+	cache := routedContainer("cache.v1", "redis:7.2.3", nil, "7.2.3", "7.2.3")
+
+	return &model.NotificationData{
+		Hostname: "host1",
+		Report: &model.CheckReport{
+			Hostname:  "host1",
+			Total:     4,
+			Available: []model.UpdateInfo{web, api},
+			Failed:    []model.UpdateInfo{db},
+			UpToDate:  []model.UpdateInfo{cache},
+		},
+	}
+}
+
+// routedNames nombres de los contenedores de las listas, en orden
+func routedNames(updates ...[]model.UpdateInfo) []string {
+	names := make([]string, 0)
+	for _, list := range updates {
+		for _, update := range list {
+			names = append(names, update.Container.Name)
+		}
+	}
+	return names
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		name  string
+		glob  string
+		value string
+		want  bool
+	}{
+		{name: "literal", glob: "web", value: "web", want: true},
+		{name: "anchored", glob: "web", value: "web-1", want: false},
+		{name: "star", glob: "web-*", value: "web-12", want: true},
+		{name: "star matches empty", glob: "web-*", value: "web-", want: true},
+		{name: "star crosses slashes", glob: "*/web:*", value: "registry.example.com/app/web:1.2.0", want: true},
+		{name: "question mark", glob: "web-?", value: "web-1", want: true},
+		{name: "question mark is one character", glob: "web-?", value: "web-12", want: false},
+		{name: "dot is literal", glob: "redis:7.2.*", value: "redis:7x2x3", want: false},
+		{name: "dot matches dot", glob: "redis:7.2.*", value: "redis:7.2.3", want: true},
+		{name: "plus is literal", glob: "a+b", value: "aab", want: false},
+		{name: "brackets are literal", glob: "[ab]", value: "a", want: false},
+		{name: "brackets match themselves", glob: "[ab]", value: "[ab]", want: true},
+		{name: "parentheses and pipe", glob: "(a|b)", value: "(a|b)", want: true},
+		{name: "caret and dollar", glob: "^web$", value: "web", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compileGlob(tt.glob).MatchString(tt.value); got != tt.want {
+				t.Errorf("compileGlob(%q).MatchString(%q) = %v, want %v", tt.glob, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		match      Match
+		want       []string
+		wantNotify bool
+	}{
+		{
+			name:       "container glob",
+			match:      Match{Containers: []string{"web-*", "cache.*"}},
+			want:       []string{"web-1", "cache.v1"},
+			wantNotify: true,
+		},
+		{
+			name:       "image glob",
+			match:      Match{Images: []string{"ghcr.io/*", "postgres:1?"}},
+			want:       []string{"api", "db"},
+			wantNotify: true,
+		},
+		{
+			name:       "label",
+			match:      Match{Labels: map[string]string{"team": "front*"}},
+			want:       []string{"web-1"},
+			wantNotify: true,
+		},
+		{
+			name:       "every label must match",
+			match:      Match{Labels: map[string]string{"team": "*", "com.docker.compose.project": "shop"}},
+			want:       []string{"web-1"},
+			wantNotify: true,
+		},
+		{
+			name:       "compose project",
+			match:      Match{ComposeProjects: []string{"sh?p"}},
+			want:       []string{"web-1", "db"},
+			wantNotify: true,
+		},
+		{
+			name:       "status",
+			match:      Match{Statuses: []model.UpdateStatus{model.StatusFailed, model.StatusUpToDate}},
+			want:       []string{"db", "cache.v1"},
+			wantNotify: true,
+		},
+		{
+			name:       "bump",
+			match:      Match{Bumps: []model.BumpLevel{model.BumpMajor}},
+			want:       []string{"api"},
+			wantNotify: true,
+		},
+		{
+			name:       "criteria are combined",
+			match:      Match{ComposeProjects: []string{"shop"}, Statuses: []model.UpdateStatus{model.StatusAvailable}},
+			want:       []string{"web-1"},
+			wantNotify: true,
+		},
+		{
+			name:       "only up to date",
+			match:      Match{Containers: []string{"cache.v1"}},
+			want:       []string{"cache.v1"},
+			wantNotify: false,
+		},
+		{
+			name:       "no match",
+			match:      Match{Containers: []string{"web"}},
+			want:       []string{},
+			wantNotify: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, err := NewRouter([]Route{{Name: tt.name, Notifiers: []string{"ops"}, Match: tt.match}})
+			if err != nil {
+				t.Fatalf("NewRouter: %v", err)
+			}
+
+			filtered, notify := router.Filter("ops", routingTestData())
+			report := filtered.Report
+			assertEqual(t, routedNames(report.Available, report.Failed, report.UpToDate), tt.want)
+			assertEqual(t, report.Total, len(tt.want))
+			assertEqual(t, report.Hostname, "host1")
+			assertEqual(t, notify, tt.wantNotify)
+		})
+	}
+}
+
+func TestRouterFilterRoutes(t *testing.T) {
+	router, err := NewRouter([]Route{
+		{Name: "frontend", Notifiers: []string{"ops", "web-team"}, Match: Match{Containers: []string{"web-*"}}},
+		{Name: "failures", Notifiers: []string{"ops"}, Match: Match{Statuses: []model.UpdateStatus{model.StatusFailed}}},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	assertEqual(t, router.Notifiers(), []string{"ops", "web-team", "ops"})
+
+	// Las rutas de un mismo notificador se suman
+	filtered, _ := router.Filter("ops", routingTestData())
+	assertEqual(t, routedNames(filtered.Report.Available, filtered.Report.Failed), []string{"web-1", "db"})
+
+	filtered, _ = router.Filter("web-team", routingTestData())
+	assertEqual(t, routedNames(filtered.Report.Available, filtered.Report.Failed), []string{"web-1"})
+
+	// Un notificador sin rutas recibe los datos sin tocar
+	data := routingTestData()
+	filtered, notify := router.Filter("email", data)
+	if filtered != data || !notify {
+		t.Errorf("notifier without routes got %+v (notify %v), want the original data", filtered, notify)
+	}
+}
+
+func TestRouterFilterChanges(t *testing.T) {
+	router, err := NewRouter([]Route{
+		{Name: "shop", Notifiers: []string{"ops"}, Match: Match{ComposeProjects: []string{"shop"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+
+	data := routingTestData()
+	web, api := data.Report.Available[0], data.Report.Available[1]
+	db, cache := data.Report.Failed[0], data.Report.UpToDate[0]
+
+	tests := []struct {
+		name       string
+		changes    model.ChangeSet
+		want       model.ChangeSet
+		wantNotify bool
+	}{
+		{
+			name: "changes are filtered",
+			changes: model.ChangeSet{
+				NewUpdates:  []model.UpdateInfo{web, api},
+				NewFailures: []model.UpdateInfo{db},
+				Resolved:    []model.UpdateInfo{cache},
+				Recovered:   []model.UpdateInfo{cache},
+			},
+			want: model.ChangeSet{
+				NewUpdates:  []model.UpdateInfo{web},
+				NewFailures: []model.UpdateInfo{db},
+			},
+			wantNotify: true,
+		},
+		{
+			name: "reminders and resolved",
+			changes: model.ChangeSet{
+				Reminders: []model.UpdateInfo{web, api},
+				Resolved:  []model.UpdateInfo{db},
+				Removed:   []model.UpdateInfo{db},
+			},
+			want: model.ChangeSet{
+				Reminders: []model.UpdateInfo{web},
+				Resolved:  []model.UpdateInfo{db},
+				Removed:   []model.UpdateInfo{db},
+			},
+			wantNotify: true,
+		},
+		{
+			// Hay cambios, pero ninguno de la ruta: no se notifica aunque
+			// el reporte filtrado tenga actualizaciones
+			name:       "no matching changes",
+			changes:    model.ChangeSet{NewUpdates: []model.UpdateInfo{api}},
+			wantNotify: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := routingTestData()
+			data.Changes = &tt.changes
+
+			filtered, notify := router.Filter("ops", data)
+			if filtered.Changes == nil {
+				t.Fatal("filtered data lost the change set")
+			}
+			changes := filtered.Changes
+			assertEqual(t, routedNames(changes.NewUpdates), routedNames(tt.want.NewUpdates))
+			assertEqual(t, routedNames(changes.NewFailures), routedNames(tt.want.NewFailures))
+			assertEqual(t, routedNames(changes.Resolved), routedNames(tt.want.Resolved))
+			assertEqual(t, routedNames(changes.Reminders), routedNames(tt.want.Reminders))
+			assertEqual(t, routedNames(changes.Recovered), routedNames(tt.want.Recovered))
+			assertEqual(t, routedNames(changes.Applied), routedNames(tt.want.Applied))
+			assertEqual(t, routedNames(changes.Removed), routedNames(tt.want.Removed))
+			assertEqual(t, routedNames(filtered.Report.Available, filtered.Report.Failed), []string{"web-1", "db"})
+			assertEqual(t, notify, tt.wantNotify)
+		})
+	}
+}
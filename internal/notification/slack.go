@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// SlackOptions configuración del notificador de Slack
+type SlackOptions struct {
+	// WebhookURL URL del incoming webhook (https://hooks.slack.com/services/...)
+	WebhookURL   string
+	Channel      string
+	Username     string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// SlackNotifier implementa Observer para incoming webhooks de Slack
+type SlackNotifier struct {
+	options   SlackOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// NewSlackNotifier crea un nuevo notificador de Slack
+func NewSlackNotifier(options SlackOptions) (*SlackNotifier, error) {
+	if options.WebhookURL == "" {
+		return nil, fmt.Errorf("slack webhook URL is required")
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "slack",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load slack template: %w", err)
+	}
+
+	return &SlackNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (sn *SlackNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	_, jsonPayload, err := sn.build(data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := postJSON(ctx, sn.client, sn.options.WebhookURL, jsonPayload)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("slack", resp)
+}
+
+// Preview implementa la interfaz Previewer
+func (sn *SlackNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, jsonPayload, err := sn.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{jsonPayload}}, nil
+}
+
+// build genera el mensaje y el cuerpo JSON de la petición
+func (sn *SlackNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := sn.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	payload := map[string]string{"text": message}
+	if sn.options.Channel != "" {
+		payload["channel"] = sn.options.Channel
+	}
+	if sn.options.Username != "" {
+		payload["username"] = sn.options.Username
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return message, jsonPayload, nil
+}
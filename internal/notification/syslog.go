@@ -0,0 +1,329 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	defaultSyslogAppName = "docker-image-checker"
+	defaultSyslogPort    = "514"
+	// syslogSDID sufijo de los elementos de datos estructurados; 32473 es el
+	// número de empresa reservado para documentación (RFC 5612)
+	syslogSDID = "@32473"
+	// syslogTimestamp formato RFC 3339 con microsegundos que exige RFC 5424
+	syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// syslogLocalSockets sockets locales donde escuchan syslog y journald
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Severidades de syslog (RFC 5424, sección 6.2.1)
+const (
+	syslogWarning = 4
+	syslogNotice  = 5
+	syslogInfo    = 6
+)
+
+// syslogFacilities códigos de facility admitidos por nombre
+var syslogFacilities = map[string]int{
+	"user": 1, "daemon": 3, "auth": 4, "syslog": 5, "cron": 9, "authpriv": 10,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogOptions configuración del notificador syslog
+type SyslogOptions struct {
+	// Network "udp", "tcp", "unix" o vacío para el socket local (/dev/log)
+	Network string
+	// Address host:puerto para udp y tcp (puerto 514 por defecto) o ruta del
+	// socket para unix
+	Address string
+	// Facility nombre de la facility (daemon por defecto, local0-local7, ...)
+	Facility string
+	// AppName campo APP-NAME de los mensajes (por defecto docker-image-checker)
+	AppName string
+}
+
+// SyslogNotifier implementa Observer enviando el resultado de cada
+// contenedor como un mensaje RFC 5424 con datos estructurados
+type SyslogNotifier struct {
+	options  SyslogOptions
+	facility int
+}
+
+// syslogRecord mensaje syslog antes de darle formato
+type syslogRecord struct {
+	severity int
+	msgID    string
+	sdID     string
+	params   [][2]string
+	message  string
+}
+
+// NewSyslogNotifier crea un nuevo notificador syslog
+func NewSyslogNotifier(options SyslogOptions) (*SyslogNotifier, error) {
+	switch options.Network {
+	case "", "unix":
+	case "udp", "tcp":
+		if options.Address == "" {
+			return nil, fmt.Errorf("syslog address is required for %s", options.Network)
+		}
+		if _, _, err := net.SplitHostPort(options.Address); err != nil {
+			options.Address = net.JoinHostPort(options.Address, defaultSyslogPort)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q (expected udp, tcp or unix)", options.Network)
+	}
+	if options.Network == "unix" && options.Address == "" {
+		return nil, fmt.Errorf("syslog socket path is required for unix")
+	}
+
+	if options.Facility == "" {
+		options.Facility = "daemon"
+	}
+	facility, ok := syslogFacilities[strings.ToLower(options.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", options.Facility)
+	}
+	if options.AppName == "" {
+		options.AppName = defaultSyslogAppName
+	}
+
+	return &SyslogNotifier{options: options, facility: facility}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (sn *SyslogNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	messages, err := sn.build(data, time.Now())
+	if err != nil {
+		return err
+	}
+
+	conn, network, err := sn.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	for _, message := range messages {
+		if err := writeSyslog(conn, network, message); err != nil {
+			return fmt.Errorf("failed to send syslog message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Preview implementa la interfaz Previewer con un mensaje por línea
+func (sn *SyslogNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	messages, err := sn.build(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{}
+	var lines []string
+	for _, message := range messages {
+		lines = append(lines, string(message))
+		preview.Payloads = append(preview.Payloads, message)
+	}
+	preview.Message = strings.Join(lines, "\n")
+	return preview, nil
+}
+
+// dial abre la conexión y devuelve la red usada; sin red configurada prueba
+// los sockets locales habituales, primero como datagrama y después como stream
+func (sn *SyslogNotifier) dial(ctx context.Context) (net.Conn, string, error) {
+	var dialer net.Dialer
+	switch sn.options.Network {
+	case "udp", "tcp":
+		conn, err := dialer.DialContext(ctx, sn.options.Network, sn.options.Address)
+		return conn, sn.options.Network, err
+	}
+
+	paths := syslogLocalSockets
+	if sn.options.Address != "" {
+		paths = []string{sn.options.Address}
+	}
+	var errs []error
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := dialer.DialContext(ctx, network, path)
+			if err == nil {
+				return conn, network, nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// writeSyslog envía un mensaje: en datagramas uno por paquete, por TCP con
+// octet counting (RFC 6587) y por sockets unix de tipo stream terminado en
+// salto de línea
+func writeSyslog(conn net.Conn, network string, message []byte) error {
+	var frame []byte
+	switch network {
+	case "tcp":
+		frame = append(strconv.AppendInt(nil, int64(len(message)), 10), ' ')
+		frame = append(frame, message...)
+	case "unix":
+		frame = append(append(frame, message...), '\n')
+	default:
+		frame = message
+	}
+	_, err := conn.Write(frame)
+	return err
+}
+
+// build genera un mensaje de resumen y uno por contenedor verificado o
+// resuelto desde la última notificación
+func (sn *SyslogNotifier) build(data *model.NotificationData, now time.Time) ([][]byte, error) {
+	if data.Report == nil {
+		return nil, fmt.Errorf("syslog notifier requires a report")
+	}
+	report := data.Report
+	hostname := data.Hostname
+	if hostname == "" {
+		hostname = report.Hostname
+	}
+
+	summarySeverity := syslogInfo
+	switch SeverityOf(data) {
+	case SeverityFailure:
+		summarySeverity = syslogWarning
+	case SeverityMajor, SeverityUpdates:
+		summarySeverity = syslogNotice
+	}
+	records := []syslogRecord{{
+		severity: summarySeverity,
+		msgID:    "summary",
+		sdID:     "report",
+		params: [][2]string{
+			{"total", strconv.Itoa(report.Total)},
+			{"available", strconv.Itoa(len(report.Available))},
+			{"failed", strconv.Itoa(len(report.Failed))},
+			{"up_to_date", strconv.Itoa(len(report.UpToDate))},
+		},
+		message: fmt.Sprintf("checked %d containers: %d updates available, %d failed",
+			report.Total, len(report.Available), len(report.Failed)),
+	}}
+
+	for _, list := range [][]model.UpdateInfo{report.Available, report.Failed, report.UpToDate} {
+		for _, update := range list {
+			records = append(records, containerRecord(update))
+		}
+	}
+
+	if data.Changes != nil {
+		resolved := []struct {
+			reason  string
+			updates []model.UpdateInfo
+		}{
+			{"applied", data.Changes.Applied},
+			{"recovered", data.Changes.Recovered},
+			{"removed", data.Changes.Removed},
+		}
+		for _, group := range resolved {
+			for _, update := range group.updates {
+				record := containerRecord(update)
+				record.severity = syslogNotice
+				record.msgID = "resolved"
+				record.params = append(record.params, [2]string{"reason", group.reason})
+				record.message = fmt.Sprintf("%s resolved (%s)", update.Container.Name, group.reason)
+				records = append(records, record)
+			}
+		}
+	}
+
+	messages := make([][]byte, 0, len(records))
+	for _, record := range records {
+		messages = append(messages, sn.format(record, hostname, now))
+	}
+	return messages, nil
+}
+
+// containerRecord mensaje con el resultado de un contenedor
+func containerRecord(update model.UpdateInfo) syslogRecord {
+	record := syslogRecord{
+		sdID: "container",
+		params: [][2]string{
+			{"name", update.Container.Name},
+			{"image", update.Container.ImageName},
+			{"current_version", update.CurrentVersion},
+			{"latest_version", update.LatestVersion},
+			{"status", string(update.Status())},
+		},
+	}
+
+	switch update.Status() {
+	case model.StatusFailed:
+		record.severity = syslogWarning
+		record.msgID = "failed"
+		record.params = append(record.params, [2]string{"error", update.Error.Error()})
+		record.message = fmt.Sprintf("check failed for %s: %v", update.Container.Name, update.Error)
+	case model.StatusAvailable:
+		record.severity = syslogNotice
+		record.msgID = "update"
+		record.params = append(record.params, [2]string{"bump", string(update.Bump())})
+		record.message = fmt.Sprintf("update available for %s: %s -> %s",
+			update.Container.Name, update.CurrentVersion, update.LatestVersion)
+	default:
+		record.severity = syslogInfo
+		record.msgID = "up_to_date"
+		record.message = fmt.Sprintf("%s is up to date (%s)", update.Container.Name, update.CurrentVersion)
+	}
+	return record
+}
+
+// format da formato RFC 5424 a un mensaje:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID param="valor" ...] MSG
+func (sn *SyslogNotifier) format(record syslogRecord, hostname string, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s [%s%s",
+		sn.facility*8+record.severity,
+		now.Format(syslogTimestamp),
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(sn.options.AppName, 48),
+		os.Getpid(),
+		syslogHeaderField(record.msgID, 32),
+		record.sdID, syslogSDID)
+	for _, param := range record.params {
+		fmt.Fprintf(&b, ` %s="%s"`, param[0], syslogParamEscaper.Replace(param[1]))
+	}
+	b.WriteString("] ")
+	b.WriteString(strings.ReplaceAll(record.message, "\n", " "))
+	return []byte(b.String())
+}
+
+// syslogParamEscaper escapa los caracteres reservados en los valores SD-PARAM
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogHeaderField limita un campo de la cabecera a ASCII imprimible sin
+// espacios y a su longitud máxima; vacío se convierte en "-"
+func syslogHeaderField(value string, maxLength int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
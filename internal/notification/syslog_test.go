@@ -0,0 +1,202 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// syslogHeader cabecera RFC 5424 seguida del elemento de datos estructurados
+var syslogHeader = regexp.MustCompile(`^<(\d+)>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}(Z|[+-]\d{2}:\d{2}) host docker-image-checker \d+ (\S+) \[(\w+)@32473( \w+="(\\.|[^"\\])*")*\] `)
+
+func syslogTestData() *model.NotificationData {
+	return &model.NotificationData{
+		Hostname: "host",
+		Report: &model.CheckReport{
+			Total: 2,
+			Available: []model.UpdateInfo{{
+				Container:      model.Container{Name: "web", ImageName: "nginx:1.25.3"},
+				CurrentVersion: "1.25.3",
+				LatestVersion:  "1.25.4",
+			}},
+			Failed: []model.UpdateInfo{{
+				Container: model.Container{Name: "db", ImageName: "postgres:15.5"},
+				Error:     errors.New(`bad "quote" \ path [x]`),
+			}},
+		},
+	}
+}
+
+// checkSyslogMessages comprueba la cabecera, los datos estructurados y el
+// escapado de los mensajes generados para syslogTestData
+func checkSyslogMessages(t *testing.T, messages []string) {
+	t.Helper()
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want summary, update and failure: %q", len(messages), messages)
+	}
+
+	// daemon (3) * 8 + severidad: notice (5) para el resumen y la
+	// actualización, warning (4) para el fallo
+	want := []struct {
+		pri, msgID, sdID string
+	}{
+		{"28", "summary", "report"},
+		{"29", "update", "container"},
+		{"28", "failed", "container"},
+	}
+	for i, message := range messages {
+		match := syslogHeader.FindStringSubmatch(message)
+		if match == nil {
+			t.Errorf("message %d is not RFC 5424: %q", i, message)
+			continue
+		}
+		if match[1] != want[i].pri || match[3] != want[i].msgID || match[4] != want[i].sdID {
+			t.Errorf("message %d: pri=%s msgid=%s sd-id=%s, want %+v", i, match[1], match[3], match[4], want[i])
+		}
+	}
+
+	if !strings.Contains(messages[1], `[container@32473 name="web" image="nginx:1.25.3" current_version="1.25.3" latest_version="1.25.4" status="available" bump="patch"] update available for web: 1.25.3 -> 1.25.4`) {
+		t.Errorf("unexpected structured data: %q", messages[1])
+	}
+	if !strings.Contains(messages[2], `error="bad \"quote\" \\ path [x\]"]`) {
+		t.Errorf("SD-PARAM value not escaped: %q", messages[2])
+	}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	notifier, err := NewSyslogNotifier(SyslogOptions{Network: "udp", Address: conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), syslogTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	checkSyslogMessages(t, readDatagrams(t, conn, 3))
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := acceptAll(listener)
+
+	notifier, err := NewSyslogNotifier(SyslogOptions{Network: "tcp", Address: listener.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), syslogTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// Cada trama es "LONGITUD ESPACIO MENSAJE" sin separadores
+	stream := bufio.NewReader(strings.NewReader(<-received))
+	var messages []string
+	for {
+		length, err := stream.ReadString(' ')
+		if err == io.EOF {
+			break
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatalf("invalid octet count %q", length)
+		}
+		message := make([]byte, n)
+		if _, err := io.ReadFull(stream, message); err != nil {
+			t.Fatalf("frame shorter than its octet count %d: %v", n, err)
+		}
+		messages = append(messages, string(message))
+	}
+	checkSyslogMessages(t, messages)
+}
+
+func TestSyslogUnixDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	notifier, err := NewSyslogNotifier(SyslogOptions{Network: "unix", Address: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), syslogTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	checkSyslogMessages(t, readDatagrams(t, conn, 3))
+}
+
+func TestSyslogUnixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := acceptAll(listener)
+
+	notifier, err := NewSyslogNotifier(SyslogOptions{Network: "unix", Address: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), syslogTestData()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// En sockets stream cada mensaje termina en salto de línea
+	checkSyslogMessages(t, strings.Split(strings.TrimSuffix(<-received, "\n"), "\n"))
+}
+
+// readDatagrams lee n datagramas
+func readDatagrams(t *testing.T, conn net.PacketConn, n int) []string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var messages []string
+	buf := make([]byte, 64*1024)
+	for len(messages) < n {
+		size, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read datagram: %v", err)
+		}
+		messages = append(messages, string(buf[:size]))
+	}
+	return messages
+}
+
+// acceptAll acepta una conexión y devuelve todo lo recibido hasta que se cierra
+func acceptAll(listener net.Listener) <-chan string {
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- ""
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		content, _ := io.ReadAll(conn)
+		received <- string(content)
+	}()
+	return received
+}
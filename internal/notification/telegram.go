@@ -2,30 +2,121 @@ package notification
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 
+	"github.com/pablopin/docker-image-checker/internal/i18n"
 	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
 )
 
+// telegramMaxMessageLength límite de la API de Telegram por mensaje
+const telegramMaxMessageLength = 4096
+
+// defaultTelegramAPIURL URL base de la Bot API
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramChat destino de los mensajes de Telegram
+type TelegramChat struct {
+	ChatID string
+	// MessageThreadID tema del foro al que se envía (0 = general)
+	MessageThreadID int
+	// DisableNotification entrega los mensajes sin sonido
+	DisableNotification bool
+}
+
+// TelegramOptions configuración del notificador de Telegram
+type TelegramOptions struct {
+	BotToken     string
+	Chats        []TelegramChat
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+	// ParseMode es ParseModeHTML, ParseModeMarkdownV2 o ParseModeNone
+	ParseMode string
+	// APIURL permite apuntar a un servidor de la Bot API distinto (por defecto api.telegram.org)
+	APIURL string
+	// SilentWhenUpToDate envía sin sonido los reportes sin actualizaciones ni fallos
+	SilentWhenUpToDate bool
+	// DocumentThreshold envía el reporte como fichero adjunto, con un resumen
+	// como pie, cuando supera este número de caracteres (0 = nunca)
+	DocumentThreshold int
+	// Progress destino de los mensajes de progreso (nil = stdout)
+	Progress io.Writer
+}
+
 // TelegramNotifier implementa Observer para notificaciones de Telegram
 type TelegramNotifier struct {
-	botToken     string
-	chatID       string
-	templatePath string
-	template     *template.Template
+	options   TelegramOptions
+	templates *templates.Set
+	// plainTemplates son las mismas plantillas sin escapado, usadas para los adjuntos
+	plainTemplates *templates.Set
+	client         *http.Client
+
+	// delivered partes ya entregadas de cada mensaje y chat, para que el
+	// reintento de un envío que falló a medias no repita las anteriores
+	deliveredMu sync.Mutex
+	delivered   map[telegramDeliveryKey]telegramDelivery
+}
+
+// telegramDeliveryKey identifica el envío de un mensaje a un chat
+type telegramDeliveryKey struct {
+	message [sha256.Size]byte
+	chatID  string
+	thread  int
+}
+
+// telegramDelivery partes entregadas de un envío
+type telegramDelivery struct {
+	parts int
+	at    time.Time
+}
+
+// telegramResponse respuesta común de la Bot API
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
 }
 
 // NewTelegramNotifier crea un nuevo notificador de Telegram
-func NewTelegramNotifier(botToken, chatID, templatePath string) (*TelegramNotifier, error) {
+func NewTelegramNotifier(options TelegramOptions) (*TelegramNotifier, error) {
+	switch options.ParseMode {
+	case ParseModeHTML, ParseModeMarkdownV2, ParseModeNone:
+	default:
+		return nil, fmt.Errorf("unsupported telegram parse mode %q", options.ParseMode)
+	}
+	if len(options.Chats) == 0 {
+		return nil, fmt.Errorf("at least one telegram chat is required")
+	}
+	for _, chat := range options.Chats {
+		if chat.ChatID == "" {
+			return nil, fmt.Errorf("telegram chat ID is required")
+		}
+	}
+	if options.APIURL == "" {
+		options.APIURL = defaultTelegramAPIURL
+	}
+	options.Progress = progressOutput(options.Progress)
+
 	notifier := &TelegramNotifier{
-		botToken:     botToken,
-		chatID:       chatID,
-		templatePath: templatePath,
+		options:   options,
+		client:    newHTTPClient(),
+		delivered: make(map[telegramDeliveryKey]telegramDelivery),
 	}
 
 	// Cargar plantilla
@@ -36,83 +127,642 @@ func NewTelegramNotifier(botToken, chatID, templatePath string) (*TelegramNotifi
 	return notifier, nil
 }
 
-// loadTemplate carga la plantilla de mensaje
+// loadTemplate carga las plantillas de mensaje y activa el escapado según el parse mode
 func (tn *TelegramNotifier) loadTemplate() error {
-	absPath, err := filepath.Abs(tn.templatePath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+	options := templates.Options{
+		Name:      "telegram",
+		Path:      tn.options.TemplatePath,
+		Events:    tn.options.EventTemplates,
+		Escaper:   escaperFor(tn.options.ParseMode),
+		CheckText: textCheckerFor(tn.options.ParseMode),
 	}
-
-	templateContent, err := os.ReadFile(absPath)
+	set, err := templates.Load(options)
 	if err != nil {
-		return fmt.Errorf("failed to read template file: %w", err)
+		return err
 	}
 
-	tmpl, err := template.New("telegram").Parse(string(templateContent))
+	options.Escaper, options.CheckText = nil, nil
+	plainSet, err := templates.Load(options)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return err
 	}
 
-	tn.template = tmpl
+	tn.templates = set
+	tn.plainTemplates = plainSet
 	return nil
 }
 
 // Notify implementa la interfaz Observer
-func (tn *TelegramNotifier) Notify(data *model.NotificationData) error {
-	fmt.Printf("🔔 Preparando notificación de Telegram...\n")
+func (tn *TelegramNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	fmt.Fprintln(tn.options.Progress, i18n.T("telegram.preparing"))
 
 	// Generar mensaje usando la plantilla
-	message, err := tn.generateMessage(data)
+	message, err := tn.templates.Render(data)
 	if err != nil {
 		return fmt.Errorf("failed to generate message: %w", err)
 	}
 
-	fmt.Printf("📝 Mensaje generado (primeros 100 chars): %.100s...\n", message)
+	fmt.Fprintln(tn.options.Progress, i18n.T("telegram.generated", message))
+
+	tn.forgetDeliveries(time.Now())
+	sum := sha256.Sum256([]byte(message))
+
+	var (
+		errs []error
+		keys []telegramDeliveryKey
+	)
+	for _, chat := range tn.chatsFor(data) {
+		key := telegramDeliveryKey{message: sum, chatID: chat.ChatID, thread: chat.MessageThreadID}
+		keys = append(keys, key)
+		if tn.asDocument(message) {
+			err = tn.sendParts(key, 1, func(int) error {
+				return tn.sendReportDocument(ctx, chat, data)
+			})
+		} else {
+			err = tn.sendLongMessage(ctx, key, chat, message)
+		}
+		if err != nil {
+			fmt.Fprintln(tn.options.Progress, i18n.T("telegram.send_error", chat.ChatID, err))
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ChatID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	tn.forgetDeliveries(time.Time{}, keys...)
+
+	fmt.Fprintln(tn.options.Progress, i18n.T("telegram.sent"))
+	return nil
+}
 
-	// Enviar mensaje via API de Telegram
-	err = tn.sendMessage(message)
+// Preview implementa la interfaz Previewer: un cuerpo por cada petición a
+// la Bot API (sendMessage en JSON o sendDocument en multipart)
+func (tn *TelegramNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, err := tn.templates.Render(data)
 	if err != nil {
-		fmt.Printf("❌ Error enviando mensaje de Telegram: %v\n", err)
-		return err
+		return nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	preview := &Preview{Message: message}
+	for _, chat := range tn.chatsFor(data) {
+		if tn.asDocument(message) {
+			body, _, err := tn.documentBody(chat, data)
+			if err != nil {
+				return nil, err
+			}
+			preview.Payloads = append(preview.Payloads, body.Bytes())
+			continue
+		}
+
+		for _, part := range splitMessage(message, telegramMaxMessageLength, tn.options.ParseMode) {
+			payload, err := json.MarshalIndent(tn.messagePayload(chat, part), "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal payload: %w", err)
+			}
+			preview.Payloads = append(preview.Payloads, payload)
+		}
+	}
+	return preview, nil
+}
+
+// chatsFor devuelve los destinos del mensaje, sin sonido si el reporte no
+// tiene nada relevante y así se ha configurado
+func (tn *TelegramNotifier) chatsFor(data *model.NotificationData) []TelegramChat {
+	silent := tn.options.SilentWhenUpToDate && SeverityOf(data) == SeverityNone
+
+	chats := make([]TelegramChat, len(tn.options.Chats))
+	for i, chat := range tn.options.Chats {
+		if silent {
+			chat.DisableNotification = true
+		}
+		chats[i] = chat
 	}
+	return chats
+}
+
+// asDocument indica si el mensaje debe enviarse como fichero adjunto
+func (tn *TelegramNotifier) asDocument(message string) bool {
+	return tn.options.DocumentThreshold > 0 && utf16Len(message) > tn.options.DocumentThreshold
+}
+
+// sendLongMessage envía un mensaje a un chat, dividiéndolo en varias partes
+// si supera el límite de Telegram
+func (tn *TelegramNotifier) sendLongMessage(ctx context.Context, key telegramDeliveryKey, chat TelegramChat, message string) error {
+	parts := splitMessage(message, telegramMaxMessageLength, tn.options.ParseMode)
+	return tn.sendParts(key, len(parts), func(i int) error {
+		err := tn.sendMessage(ctx, chat, parts[i])
+		if err != nil && len(parts) > 1 {
+			return fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+		}
+		return err
+	})
+}
+
+// sendParts envía las partes de un mensaje empezando por la primera que no
+// se entregó en un intento anterior. Así, cuando el outbox reintenta un
+// mensaje que falló en la parte N, no se repiten las partes 1..N-1 ni los
+// chats que ya lo recibieron completo.
+func (tn *TelegramNotifier) sendParts(key telegramDeliveryKey, parts int, send func(i int) error) error {
+	tn.deliveredMu.Lock()
+	start := tn.delivered[key].parts
+	tn.deliveredMu.Unlock()
 
-	fmt.Printf("✅ Mensaje de Telegram enviado exitosamente\n")
+	for i := start; i < parts; i++ {
+		if err := send(i); err != nil {
+			return err
+		}
+		tn.deliveredMu.Lock()
+		tn.delivered[key] = telegramDelivery{parts: i + 1, at: time.Now()}
+		tn.deliveredMu.Unlock()
+	}
 	return nil
 }
 
-// generateMessage genera el mensaje usando la plantilla
-func (tn *TelegramNotifier) generateMessage(data *model.NotificationData) (string, error) {
-	var buf bytes.Buffer
-	if err := tn.template.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+// forgetDeliveries olvida los envíos indicados y los que lleven más tiempo
+// sin reintentarse del que el outbox guarda una notificación
+func (tn *TelegramNotifier) forgetDeliveries(now time.Time, keys ...telegramDeliveryKey) {
+	tn.deliveredMu.Lock()
+	defer tn.deliveredMu.Unlock()
+
+	for _, key := range keys {
+		delete(tn.delivered, key)
+	}
+	if now.IsZero() {
+		return
+	}
+	for key, delivery := range tn.delivered {
+		if now.Sub(delivery.at) > defaultOutboxMaxAge {
+			delete(tn.delivered, key)
+		}
 	}
-	return buf.String(), nil
 }
 
 // sendMessage envía el mensaje via API de Telegram
-func (tn *TelegramNotifier) sendMessage(message string) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", tn.botToken)
+func (tn *TelegramNotifier) sendMessage(ctx context.Context, chat TelegramChat, message string) error {
+	return tn.call(ctx, "sendMessage", tn.messagePayload(chat, message))
+}
+
+// messagePayload cuerpo de sendMessage para un chat
+func (tn *TelegramNotifier) messagePayload(chat TelegramChat, message string) map[string]interface{} {
+	payload := tn.basePayload(chat)
+	payload["text"] = message
+	payload["link_preview_options"] = map[string]bool{"is_disabled": true}
+	return payload
+}
+
+// sendReportDocument adjunta el reporte completo como fichero con un resumen como pie
+func (tn *TelegramNotifier) sendReportDocument(ctx context.Context, chat TelegramChat, data *model.NotificationData) error {
+	body, contentType, err := tn.documentBody(chat, data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tn.methodURL("sendDocument"), body)
+	if err != nil {
+		return fmt.Errorf("failed to build document request: %w", redactToken(err, tn.options.BotToken))
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := tn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram document: %w", redactToken(err, tn.options.BotToken))
+	}
+	defer resp.Body.Close()
 
+	return parseTelegramResponse(resp, nil)
+}
+
+// documentBody genera el cuerpo multipart de sendDocument y su Content-Type
+func (tn *TelegramNotifier) documentBody(chat TelegramChat, data *model.NotificationData) (*bytes.Buffer, string, error) {
+	content, err := tn.plainTemplates.Render(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate document: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, value := range tn.basePayload(chat) {
+		if err := writer.WriteField(key, fmt.Sprint(value)); err != nil {
+			return nil, "", fmt.Errorf("failed to build document request: %w", err)
+		}
+	}
+	if err := writer.WriteField("caption", escaperFor(tn.options.ParseMode)(reportSummary(data))); err != nil {
+		return nil, "", fmt.Errorf("failed to build document request: %w", err)
+	}
+
+	file, err := writer.CreateFormFile("document", documentName(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build document request: %w", err)
+	}
+	if _, err := io.WriteString(file, content); err != nil {
+		return nil, "", fmt.Errorf("failed to build document request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to build document request: %w", err)
+	}
+
+	return &body, writer.FormDataContentType(), nil
+}
+
+// basePayload parámetros comunes a todos los envíos a un chat
+func (tn *TelegramNotifier) basePayload(chat TelegramChat) map[string]interface{} {
 	payload := map[string]interface{}{
-		"chat_id":    tn.chatID,
-		"text":       message,
-		"parse_mode": "HTML",
+		"chat_id": chat.ChatID,
 	}
+	if tn.options.ParseMode != ParseModeNone {
+		payload["parse_mode"] = tn.options.ParseMode
+	}
+	if chat.MessageThreadID != 0 {
+		payload["message_thread_id"] = chat.MessageThreadID
+	}
+	if chat.DisableNotification {
+		payload["disable_notification"] = true
+	}
+	return payload
+}
 
+// call invoca un método de la Bot API con un cuerpo JSON
+func (tn *TelegramNotifier) call(ctx context.Context, method string, payload interface{}) error {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
+	resp, err := postJSON(ctx, tn.client, tn.methodURL(method), jsonPayload)
 	if err != nil {
-		return fmt.Errorf("failed to send telegram message: %w", err)
+		// El error de net/http incluye la URL, que contiene el token del bot
+		return fmt.Errorf("failed to send telegram message: %w", redactToken(err, tn.options.BotToken))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	return parseTelegramResponse(resp, nil)
+}
+
+// methodURL construye la URL de un método de la Bot API
+func (tn *TelegramNotifier) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(tn.options.APIURL, "/"), tn.options.BotToken, method)
+}
+
+// reportSummary resumen de una línea usado como pie de los adjuntos
+func reportSummary(data *model.NotificationData) string {
+	if data.Report == nil {
+		return messageTitle(data)
+	}
+	return messageTitle(data) + "\n" +
+		i18n.T("telegram.summary", len(data.Report.Available), len(data.Report.Failed), data.Report.Total)
+}
+
+// documentName nombre del fichero adjunto con el reporte
+func documentName(data *model.NotificationData) string {
+	name := "docker-image-checker"
+	if data.Hostname != "" {
+		name += "-" + data.Hostname
+	}
+	if data.Report != nil && !data.Report.Timestamp.IsZero() {
+		name += "-" + data.Report.Timestamp.Format("20060102-150405")
+	}
+	return name + ".txt"
+}
+
+// ParseTelegramChat interpreta un destino con formato "<chat-id>[:<message-thread-id>]"
+func ParseTelegramChat(value string) (TelegramChat, error) {
+	chatID, thread, hasThread := strings.Cut(value, ":")
+	chat := TelegramChat{ChatID: chatID}
+	if hasThread {
+		threadID, err := strconv.Atoi(thread)
+		if err != nil {
+			return chat, fmt.Errorf("invalid message thread ID %q", thread)
+		}
+		chat.MessageThreadID = threadID
+	}
+	return chat, nil
+}
+
+// parseTelegramResponse comprueba la respuesta de la Bot API y decodifica result
+func parseTelegramResponse(resp *http.Response, result interface{}) error {
+	var body telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("telegram API returned status code: %d", resp.StatusCode)
+		}
+		return fmt.Errorf("failed to decode telegram response: %w", err)
+	}
+
+	if !body.OK || resp.StatusCode != http.StatusOK {
+		if body.Description != "" {
+			return fmt.Errorf("telegram API returned status code %d: %s", resp.StatusCode, body.Description)
+		}
 		return fmt.Errorf("telegram API returned status code: %d", resp.StatusCode)
 	}
 
+	if result != nil {
+		if err := json.Unmarshal(body.Result, result); err != nil {
+			return fmt.Errorf("failed to decode telegram result: %w", err)
+		}
+	}
 	return nil
 }
+
+// redactToken elimina el token del bot de un error
+func redactToken(err error, token string) error {
+	if token == "" || !strings.Contains(err.Error(), token) {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "<redacted>"))
+}
+
+// splitMessage divide un mensaje en partes de como máximo limit unidades
+// UTF-16 (la medida que usa Telegram), cortando por saltos de línea siempre
+// que es posible. Nunca corta dentro de una etiqueta o entidad HTML, de un
+// escape o de un enlace de MarkdownV2, y los formatos que siguen abiertos en
+// el corte (<b>, <pre>, *negrita*, bloques de código...) se cierran al final
+// de la parte y se reabren al principio de la siguiente, porque Telegram
+// rechaza las partes desequilibradas.
+func splitMessage(message string, limit int, parseMode string) []string {
+	if utf16Len(message) <= limit {
+		return []string{message}
+	}
+
+	var (
+		parts []string
+		open  []markup
+	)
+	for message != "" {
+		var part string
+		part, message, open = cutMessage(message, limit, parseMode, open)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// utf16Len longitud de un texto en unidades UTF-16
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// markup formato abierto en un punto del mensaje
+type markup struct {
+	// open texto que lo abre, con sus atributos (<a href="...">, ```go)
+	open string
+	// close texto que lo cierra (</a>, ```)
+	close string
+}
+
+// reopenMarkup texto que reabre los formatos al principio de una parte
+func reopenMarkup(open []markup) string {
+	var b strings.Builder
+	for _, m := range open {
+		b.WriteString(m.open)
+	}
+	return b.String()
+}
+
+// closeMarkup texto que cierra los formatos al final de una parte
+func closeMarkup(open []markup) string {
+	var b strings.Builder
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString(open[i].close)
+	}
+	return b.String()
+}
+
+// cutPoint punto del mensaje donde se puede cortar
+type cutPoint struct {
+	index int
+	open  []markup
+}
+
+// cutMessage toma la siguiente parte de un mensaje empezando con los
+// formatos open reabiertos. Devuelve la parte, el resto del mensaje y los
+// formatos que siguen abiertos al principio del resto. Si no hay ningún
+// punto de corte seguro se corta en el límite.
+func cutMessage(s string, limit int, parseMode string, open []markup) (string, string, []markup) {
+	prefix := reopenMarkup(open)
+	scanner := markupScanner{parseMode: parseMode, open: open}
+	size := utf16Len(prefix)
+
+	var last, line *cutPoint
+	i := 0
+	for i < len(s) {
+		before := scanner.open
+		width := scanner.next(s[i:])
+		tokenSize := utf16Len(s[i : i+width])
+		if size+tokenSize > limit {
+			cut := line
+			if cut == nil {
+				cut = last
+			}
+			if cut != nil {
+				head := strings.TrimRight(s[:cut.index], "\n")
+				return prefix + head + closeMarkup(cut.open), s[cut.index:], cut.open
+			}
+			// Ningún corte cabe: se corta el token por caracteres
+			n := i + cutRunes(s[i:i+width], limit-size)
+			if n == 0 {
+				_, n = utf8.DecodeRuneInString(s)
+			}
+			return prefix + s[:n], s[n:], before
+		}
+		i += width
+		size += tokenSize
+
+		if !scanner.safe() || size+utf16Len(closeMarkup(scanner.open)) > limit {
+			continue
+		}
+		point := &cutPoint{index: i, open: scanner.open}
+		last = point
+		if s[i-1] == '\n' {
+			line = point
+		}
+	}
+	return prefix + s, "", nil
+}
+
+// cutRunes bytes de s que caben en limit unidades UTF-16 sin partir caracteres
+func cutRunes(s string, limit int) int {
+	n := 0
+	for i, r := range s {
+		n += utf16.RuneLen(r)
+		if n > limit {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// markupScanner recorre un mensaje token a token llevando la cuenta de los
+// formatos abiertos según el parse mode
+type markupScanner struct {
+	parseMode string
+	// open pila de formatos abiertos; se copia al apilar para que los
+	// puntos de corte puedan guardar la suya sin copiarla
+	open []markup
+	// pending el último token no se puede separar del siguiente
+	pending bool
+}
+
+// safe indica si se puede cortar tras el último token
+func (sc *markupScanner) safe() bool {
+	return !sc.pending
+}
+
+// next consume el token al principio de s y devuelve su longitud en bytes
+func (sc *markupScanner) next(s string) int {
+	sc.pending = false
+	switch sc.parseMode {
+	case ParseModeHTML:
+		return sc.nextHTML(s)
+	case ParseModeMarkdownV2:
+		return sc.nextMarkdownV2(s)
+	}
+	_, width := utf8.DecodeRuneInString(s)
+	return width
+}
+
+// nextHTML consume una etiqueta, una entidad o un carácter
+func (sc *markupScanner) nextHTML(s string) int {
+	switch s[0] {
+	case '<':
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return 1
+		}
+		tag := s[:end+1]
+		if strings.HasPrefix(tag, "</") {
+			sc.pop(htmlTagName(tag[2:]), func(m markup) string { return htmlTagName(m.open[1:]) })
+		} else {
+			name := htmlTagName(tag[1:])
+			sc.push(markup{open: tag, close: "</" + name + ">"})
+		}
+		return end + 1
+	case '&':
+		end := strings.IndexFunc(s, func(r rune) bool { return r == ';' || unicode.IsSpace(r) })
+		if end > 0 && s[end] == ';' {
+			return end + 1
+		}
+	}
+	_, width := utf8.DecodeRuneInString(s)
+	return width
+}
+
+// htmlTagName nombre en minúsculas de una etiqueta sin el '<' inicial
+func htmlTagName(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool { return r == '>' || r == '/' || unicode.IsSpace(r) })
+	if end < 0 {
+		end = len(s)
+	}
+	return strings.ToLower(s[:end])
+}
+
+// nextMarkdownV2 consume un escape, un marcador de formato, un enlace
+// completo o un carácter
+func (sc *markupScanner) nextMarkdownV2(s string) int {
+	if s[0] == '\\' {
+		if len(s) == 1 {
+			return 1
+		}
+		_, width := utf8.DecodeRuneInString(s[1:])
+		return 1 + width
+	}
+
+	// Dentro del código sólo cuenta el marcador que lo cierra
+	if n := len(sc.open); n > 0 && strings.HasPrefix(sc.open[n-1].open, "`") {
+		code := sc.open[n-1]
+		switch {
+		case code.open != "`" && strings.HasPrefix(s, "```"):
+			sc.open = sc.open[:n-1]
+			return 3
+		case code.open == "`" && s[0] == '`':
+			sc.open = sc.open[:n-1]
+			return 1
+		}
+		_, width := utf8.DecodeRuneInString(s)
+		return width
+	}
+
+	switch {
+	case strings.HasPrefix(s, "```"):
+		// La apertura incluye el lenguaje hasta el salto de línea
+		end := strings.IndexByte(s, '\n')
+		if end < 0 {
+			end = 2
+		}
+		sc.push(markup{open: s[:end+1], close: "\n```"})
+		return end + 1
+	case s[0] == '`':
+		sc.push(markup{open: "`", close: "`"})
+		return 1
+	case strings.HasPrefix(s, "||"), strings.HasPrefix(s, "__"):
+		sc.toggle(s[:2])
+		return 2
+	case s[0] == '*', s[0] == '_', s[0] == '~':
+		sc.toggle(s[:1])
+		return 1
+	case s[0] == '[', strings.HasPrefix(s, "!["):
+		// Un enlace no se puede reabrir sin su URL: se trata como un token
+		if end := markdownLinkEnd(s); end > 0 {
+			return end
+		}
+	}
+	_, width := utf8.DecodeRuneInString(s)
+	return width
+}
+
+// markdownLinkEnd longitud de un enlace [texto](url) al principio de s, o 0
+func markdownLinkEnd(s string) int {
+	text := markdownIndex(s, "](")
+	if text < 0 {
+		return 0
+	}
+	url := markdownIndex(s[text+2:], ")")
+	if url < 0 {
+		return 0
+	}
+	return text + 2 + url + 1
+}
+
+// markdownIndex como strings.Index pero saltándose los escapes
+func markdownIndex(s, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// push apila un formato sin modificar las pilas guardadas en los puntos de
+// corte. Una apertura no se separa de su contenido.
+func (sc *markupScanner) push(m markup) {
+	sc.open = append(sc.open[:len(sc.open):len(sc.open)], m)
+	sc.pending = true
+}
+
+// pop desapila hasta el último formato con ese nombre, si lo hay
+func (sc *markupScanner) pop(name string, nameOf func(markup) string) {
+	for i := len(sc.open) - 1; i >= 0; i-- {
+		if nameOf(sc.open[i]) == name {
+			sc.open = sc.open[:i]
+			return
+		}
+	}
+}
+
+// toggle abre un formato de MarkdownV2 o lo cierra si ya estaba abierto
+func (sc *markupScanner) toggle(marker string) {
+	for i := len(sc.open) - 1; i >= 0; i-- {
+		if sc.open[i].open == marker {
+			sc.open = sc.open[:i]
+			return
+		}
+	}
+	sc.push(markup{open: marker, close: marker})
+}
@@ -0,0 +1,289 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+const (
+	// defaultPollTimeout tiempo máximo de espera de cada llamada a getUpdates
+	defaultPollTimeout = 50 * time.Second
+	// pollRetryDelay espera tras un error antes de volver a consultar
+	pollRetryDelay = 5 * time.Second
+	// defaultIgnoreDuration duración de /ignore cuando no se indica
+	defaultIgnoreDuration = 7 * 24 * time.Hour
+)
+
+// BotHandler acciones de la aplicación que el bot expone como comandos
+type BotHandler interface {
+	// RunCheck ejecuta una verificación completa y devuelve el reporte; el bot
+	// puede llamarlo concurrentemente y debe serializar las verificaciones
+	RunCheck(ctx context.Context) (*model.CheckReport, error)
+	// LastReport devuelve el último reporte o nil si aún no hay ninguno
+	LastReport() *model.CheckReport
+	// Ignore silencia las notificaciones de un contenedor durante un tiempo
+	Ignore(container string, duration time.Duration) error
+}
+
+// TelegramBotOptions configuración del bot de comandos de Telegram
+type TelegramBotOptions struct {
+	BotToken string
+	// APIURL permite apuntar a un servidor de la Bot API distinto (por defecto api.telegram.org)
+	APIURL string
+	// AllowedChatIDs y AllowedUserIDs limitan quién puede usar el bot;
+	// un mensaje se atiende si su chat o su remitente están en la lista
+	AllowedChatIDs []int64
+	AllowedUserIDs []int64
+	PollTimeout    time.Duration
+}
+
+// TelegramBot atiende comandos de Telegram mediante long polling (getUpdates)
+type TelegramBot struct {
+	options   TelegramBotOptions
+	handler   BotHandler
+	templates *templates.Set
+	client    *http.Client
+	offset    int64
+	// checks espera a las verificaciones lanzadas con /check antes de salir
+	checks sync.WaitGroup
+}
+
+// telegramUpdate subconjunto de Update de la Bot API que usa el bot
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		MessageID int64  `json:"message_id"`
+		Text      string `json:"text"`
+		Chat      struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From *struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		MessageThreadID int64 `json:"message_thread_id"`
+	} `json:"message"`
+}
+
+// NewTelegramBot crea un nuevo bot de comandos
+func NewTelegramBot(options TelegramBotOptions, handler BotHandler) (*TelegramBot, error) {
+	if options.BotToken == "" {
+		return nil, fmt.Errorf("telegram bot token is required")
+	}
+	if len(options.AllowedChatIDs) == 0 && len(options.AllowedUserIDs) == 0 {
+		return nil, fmt.Errorf("telegram bot requires at least one allowed chat or user ID")
+	}
+	if options.APIURL == "" {
+		options.APIURL = defaultTelegramAPIURL
+	}
+	if options.PollTimeout <= 0 {
+		options.PollTimeout = defaultPollTimeout
+	}
+
+	tmpl, err := templates.Load(templates.Options{Name: "telegram-bot"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bot template: %w", err)
+	}
+
+	return &TelegramBot{
+		options:   options,
+		handler:   handler,
+		templates: tmpl,
+		// El timeout del cliente debe superar el del long polling
+		client: &http.Client{Timeout: options.PollTimeout + defaultHTTPTimeout},
+	}, nil
+}
+
+// Run consulta getUpdates hasta que se cancela el contexto y espera a que
+// terminen las verificaciones en curso
+func (tb *TelegramBot) Run(ctx context.Context) error {
+	defer tb.checks.Wait()
+
+	for {
+		updates, err := tb.getUpdates(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Telegram bot: failed to get updates: %v", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			tb.offset = update.UpdateID + 1
+			tb.handleUpdate(ctx, update)
+		}
+	}
+}
+
+// getUpdates obtiene los mensajes pendientes con long polling
+func (tb *TelegramBot) getUpdates(ctx context.Context) ([]telegramUpdate, error) {
+	payload := map[string]interface{}{
+		"offset":          tb.offset,
+		"timeout":         int(tb.options.PollTimeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}
+
+	var updates []telegramUpdate
+	if err := tb.call(ctx, "getUpdates", payload, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// handleUpdate procesa un mensaje si proviene de un chat o usuario autorizado
+func (tb *TelegramBot) handleUpdate(ctx context.Context, update telegramUpdate) {
+	message := update.Message
+	if message == nil || !strings.HasPrefix(message.Text, "/") {
+		return
+	}
+
+	var userID int64
+	if message.From != nil {
+		userID = message.From.ID
+	}
+	if !slices.Contains(tb.options.AllowedChatIDs, message.Chat.ID) &&
+		!slices.Contains(tb.options.AllowedUserIDs, userID) {
+		log.Printf("Telegram bot: ignoring command from unauthorized chat %d (user %d)", message.Chat.ID, userID)
+		return
+	}
+
+	reply := func(text string) {
+		for _, part := range splitMessage(text, telegramMaxMessageLength, ParseModeNone) {
+			payload := map[string]interface{}{
+				"chat_id": message.Chat.ID,
+				"text":    part,
+			}
+			if message.MessageThreadID != 0 {
+				payload["message_thread_id"] = message.MessageThreadID
+			}
+			if err := tb.call(ctx, "sendMessage", payload, nil); err != nil {
+				log.Printf("Telegram bot: failed to reply: %v", err)
+				return
+			}
+		}
+	}
+
+	command, args := parseCommand(message.Text)
+	switch command {
+	case "check":
+		reply(i18n.T("bot.checking"))
+		// La verificación puede tardar minutos: se ejecuta aparte para seguir
+		// atendiendo comandos, y el handler serializa las que coinciden
+		tb.checks.Add(1)
+		go func() {
+			defer tb.checks.Done()
+			report, err := tb.handler.RunCheck(ctx)
+			if err != nil {
+				reply(i18n.T("bot.check_error", err))
+				return
+			}
+			reply(tb.renderReport(report))
+		}()
+	case "status":
+		report := tb.handler.LastReport()
+		if report == nil {
+			reply(i18n.T("bot.no_report"))
+			return
+		}
+		reply(tb.renderReport(report))
+	case "ignore":
+		if len(args) == 0 {
+			reply(i18n.T("bot.ignore_usage"))
+			return
+		}
+		duration := defaultIgnoreDuration
+		if len(args) > 1 {
+			d, err := ParseDuration(args[1])
+			if err != nil || d <= 0 {
+				reply(i18n.T("bot.invalid_duration", args[1]))
+				return
+			}
+			duration = d
+		}
+		if err := tb.handler.Ignore(args[0], duration); err != nil {
+			reply(i18n.T("bot.ignore_error", args[0], err))
+			return
+		}
+		reply(i18n.T("bot.ignored", args[0], time.Now().Add(duration).Format("2006-01-02 15:04")))
+	case "help", "start":
+		reply(i18n.T("bot.help"))
+	default:
+		reply(i18n.T("bot.unknown"))
+	}
+}
+
+// renderReport genera el texto de respuesta para un reporte
+func (tb *TelegramBot) renderReport(report *model.CheckReport) string {
+	text, err := tb.templates.Render(&model.NotificationData{
+		Report:   report,
+		Hostname: report.Hostname,
+	})
+	if err != nil {
+		return fmt.Sprintf("❌ %v", err)
+	}
+	return text
+}
+
+// call invoca un método de la Bot API y decodifica el resultado
+func (tb *TelegramBot) call(ctx context.Context, method string, payload, result interface{}) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(tb.options.APIURL, "/"), tb.options.BotToken, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create telegram request: %w", redactToken(err, tb.options.BotToken))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := tb.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram %s failed: %w", method, redactToken(err, tb.options.BotToken))
+	}
+	defer resp.Body.Close()
+
+	return parseTelegramResponse(resp, result)
+}
+
+// parseCommand separa el comando (sin "/" ni "@bot") de sus argumentos
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	command := strings.TrimPrefix(fields[0], "/")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), fields[1:]
+}
+
+// ParseDuration interpreta duraciones de Go admitiendo además días (ej: "7d")
+func ParseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,238 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const testBotToken = "123:secret"
+
+// fakeBotAPI servidor de la Bot API que entrega los mensajes encolados por
+// getUpdates y publica cada sendMessage en sent
+type fakeBotAPI struct {
+	t       *testing.T
+	mu      sync.Mutex
+	updates []map[string]interface{}
+	sent    chan map[string]interface{}
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/bot"+testBotToken+"/") {
+		f.t.Errorf("unexpected path %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		f.t.Errorf("invalid payload: %v", err)
+	}
+
+	var result interface{}
+	switch path.Base(r.URL.Path) {
+	case "getUpdates":
+		offset := int64(payload["offset"].(float64))
+		var pending []map[string]interface{}
+		f.mu.Lock()
+		for _, update := range f.updates {
+			if update["update_id"].(int64) >= offset {
+				pending = append(pending, update)
+			}
+		}
+		f.mu.Unlock()
+		if len(pending) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		result = pending
+	case "sendMessage":
+		f.sent <- payload
+		result = map[string]interface{}{"message_id": 1}
+	default:
+		f.t.Errorf("unexpected method %s", r.URL.Path)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
+
+// push encola un mensaje de texto de un chat y un usuario
+func (f *fakeBotAPI) push(chatID, userID int64, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, map[string]interface{}{
+		"update_id": int64(len(f.updates) + 1),
+		"message": map[string]interface{}{
+			"message_id": len(f.updates) + 1,
+			"text":       text,
+			"chat":       map[string]interface{}{"id": chatID},
+			"from":       map[string]interface{}{"id": userID},
+		},
+	})
+}
+
+// next espera la siguiente respuesta del bot
+func (f *fakeBotAPI) next(t *testing.T) (int64, string) {
+	t.Helper()
+	select {
+	case payload := <-f.sent:
+		return int64(payload["chat_id"].(float64)), payload["text"].(string)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a bot reply")
+		return 0, ""
+	}
+}
+
+// fakeBotHandler BotHandler cuyo RunCheck espera a release
+type fakeBotHandler struct {
+	release chan struct{}
+	mu      sync.Mutex
+	report  *model.CheckReport
+	ignored map[string]time.Duration
+}
+
+func (h *fakeBotHandler) RunCheck(ctx context.Context) (*model.CheckReport, error) {
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	report := &model.CheckReport{
+		Hostname: "host",
+		Total:    1,
+		Available: []model.UpdateInfo{{
+			Container:      model.Container{Name: "web", ImageName: "nginx:1.25.3"},
+			CurrentVersion: "1.25.3",
+			LatestVersion:  "1.25.4",
+		}},
+	}
+	h.mu.Lock()
+	h.report = report
+	h.mu.Unlock()
+	return report, nil
+}
+
+func (h *fakeBotHandler) LastReport() *model.CheckReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.report
+}
+
+func (h *fakeBotHandler) Ignore(container string, duration time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ignored[container] = duration
+	return nil
+}
+
+// startBot arranca un bot contra una Bot API falsa; solo atiende el chat 100
+// y el usuario 42
+func startBot(t *testing.T) (*fakeBotAPI, *fakeBotHandler) {
+	api := &fakeBotAPI{t: t, sent: make(chan map[string]interface{}, 16)}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	handler := &fakeBotHandler{release: make(chan struct{}), ignored: make(map[string]time.Duration)}
+	bot, err := NewTelegramBot(TelegramBotOptions{
+		BotToken:       testBotToken,
+		APIURL:         server.URL,
+		AllowedChatIDs: []int64{100},
+		AllowedUserIDs: []int64{42},
+		PollTimeout:    time.Second,
+	}, handler)
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- bot.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	})
+	return api, handler
+}
+
+func TestTelegramBotAllowlist(t *testing.T) {
+	api, _ := startBot(t)
+
+	api.push(999, 7, "/help")
+	api.push(999, 42, "/help@my_bot")
+	api.push(100, 7, "/help")
+
+	for _, wantChat := range []int64{999, 100} {
+		chat, text := api.next(t)
+		if chat != wantChat {
+			t.Errorf("reply sent to chat %d, want %d", chat, wantChat)
+		}
+		if text != i18n.T("bot.help") {
+			t.Errorf("got %q, want the help text", text)
+		}
+	}
+	select {
+	case payload := <-api.sent:
+		t.Errorf("unexpected reply %v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestTelegramBotCommands(t *testing.T) {
+	api, handler := startBot(t)
+
+	api.push(100, 7, "/status")
+	if _, text := api.next(t); text != i18n.T("bot.no_report") {
+		t.Errorf("/status without report: got %q", text)
+	}
+
+	api.push(100, 7, "/ignore web 2d")
+	if _, text := api.next(t); !strings.Contains(text, "web") {
+		t.Errorf("/ignore: got %q", text)
+	}
+	if got := handler.ignored["web"]; got != 48*time.Hour {
+		t.Errorf("ignored for %v, want 48h", got)
+	}
+
+	api.push(100, 7, "/ignore web soon")
+	if _, text := api.next(t); text != i18n.T("bot.invalid_duration", "soon") {
+		t.Errorf("/ignore with invalid duration: got %q", text)
+	}
+
+	api.push(100, 7, "/nope")
+	if _, text := api.next(t); text != i18n.T("bot.unknown") {
+		t.Errorf("unknown command: got %q", text)
+	}
+}
+
+func TestTelegramBotCheckDoesNotBlockPolling(t *testing.T) {
+	api, handler := startBot(t)
+
+	api.push(100, 7, "/check")
+	if _, text := api.next(t); text != i18n.T("bot.checking") {
+		t.Errorf("/check: got %q", text)
+	}
+
+	// Mientras la verificación sigue en curso el bot atiende otros comandos
+	api.push(100, 7, "/help")
+	if _, text := api.next(t); text != i18n.T("bot.help") {
+		t.Errorf("/help during a check: got %q", text)
+	}
+
+	close(handler.release)
+	if _, text := api.next(t); !strings.Contains(text, "web") || !strings.Contains(text, "1.25.4") {
+		t.Errorf("/check report: got %q", text)
+	}
+
+	api.push(100, 7, "/status")
+	if _, text := api.next(t); !strings.Contains(text, "web") {
+		t.Errorf("/status: got %q", text)
+	}
+}
@@ -0,0 +1,449 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		parseMode string
+		limit     int
+		// valid comprueba que una parte no corta el formato
+		valid func(part string) bool
+	}{
+		{
+			name:      "html tags and entities",
+			message:   strings.Repeat("<b>web</b> &amp; <code>db</code> ", 20),
+			parseMode: ParseModeHTML,
+			limit:     23,
+			valid: func(part string) bool {
+				return strings.Count(part, "<") == strings.Count(part, ">") &&
+					strings.Count(part, "&") == strings.Count(part, ";")
+			},
+		},
+		{
+			name:      "markdownv2 escapes",
+			message:   strings.Repeat(`web\-1 \(nginx:1\.25\) `, 20),
+			parseMode: ParseModeMarkdownV2,
+			limit:     10,
+			valid: func(part string) bool {
+				trailing := len(part) - len(strings.TrimRight(part, `\`))
+				return trailing%2 == 0
+			},
+		},
+		{
+			name:      "surrogate pairs",
+			message:   strings.Repeat("🔄", 50),
+			parseMode: ParseModeNone,
+			limit:     7,
+			valid: func(part string) bool {
+				return utf16Len(part) == 6
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitMessage(tt.message, tt.limit, tt.parseMode)
+			if len(parts) < 2 {
+				t.Fatalf("expected several parts, got %d", len(parts))
+			}
+			if got := strings.Join(parts, ""); got != tt.message {
+				t.Errorf("parts do not add up to the message:\n%q\n%q", got, tt.message)
+			}
+			for i, part := range parts {
+				if size := utf16Len(part); size > tt.limit {
+					t.Errorf("part %d has %d UTF-16 units, limit %d", i, size, tt.limit)
+				}
+				if i < len(parts)-1 && !tt.valid(part) {
+					t.Errorf("part %d breaks the markup: %q", i, part)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitMessageByLines(t *testing.T) {
+	message := "<b>first</b>\nsecond &amp; line\nthird"
+	parts := splitMessage(message, 32, ParseModeHTML)
+	want := []string{"<b>first</b>\nsecond &amp; line", "third"}
+	assertEqual(t, parts, want)
+}
+
+func TestSplitMessageFormatting(t *testing.T) {
+	code := strings.Repeat("fmt.Println(x)\n", 20)
+	tests := []struct {
+		name      string
+		message   string
+		parseMode string
+		limit     int
+		// open y close son lo que cada parte debe reabrir y cerrar
+		open, close string
+		// sep une el contenido de las partes cuando se corta por líneas
+		content, sep string
+	}{
+		{
+			name:      "html bold",
+			message:   "<b>" + strings.Repeat("web api db ", 20) + "</b>",
+			parseMode: ParseModeHTML,
+			limit:     40,
+			open:      "<b>",
+			close:     "</b>",
+			content:   strings.Repeat("web api db ", 20),
+		},
+		{
+			name:      "html pre block",
+			message:   `<pre><code class="language-go">` + code + "</code></pre>",
+			parseMode: ParseModeHTML,
+			limit:     100,
+			open:      `<pre><code class="language-go">`,
+			close:     "</code></pre>",
+			content:   code,
+			sep:       "\n",
+		},
+		{
+			name:      "markdownv2 bold",
+			message:   "*" + strings.Repeat(`web\-1 api db `, 20) + "*",
+			parseMode: ParseModeMarkdownV2,
+			limit:     40,
+			open:      "*",
+			close:     "*",
+			content:   strings.Repeat(`web\-1 api db `, 20),
+		},
+		{
+			name:      "markdownv2 code block",
+			message:   "```go\n" + code + "```",
+			parseMode: ParseModeMarkdownV2,
+			limit:     100,
+			open:      "```go\n",
+			close:     "\n```",
+			content:   code,
+			sep:       "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := splitMessage(tt.message, tt.limit, tt.parseMode)
+			if len(parts) < 2 {
+				t.Fatalf("expected several parts, got %d", len(parts))
+			}
+
+			var contents []string
+			for i, part := range parts {
+				if size := utf16Len(part); size > tt.limit {
+					t.Errorf("part %d has %d UTF-16 units, limit %d", i, size, tt.limit)
+				}
+				if !balanced(part, tt.parseMode) {
+					t.Errorf("part %d is unbalanced: %q", i, part)
+				}
+				// El cierre de un bloque de código se come el salto de línea final
+				closing := strings.TrimPrefix(tt.close, "\n")
+				if !strings.HasPrefix(part, tt.open) || !strings.HasSuffix(part, closing) {
+					t.Errorf("part %d is not wrapped in %q and %q: %q", i, tt.open, tt.close, part)
+					continue
+				}
+				content := strings.TrimSuffix(strings.TrimPrefix(part, tt.open), closing)
+				contents = append(contents, strings.TrimSuffix(content, "\n"))
+			}
+			if got := strings.Join(contents, tt.sep); got != strings.TrimSuffix(tt.content, "\n") {
+				t.Errorf("parts do not add up to the content:\n%q\n%q", got, tt.content)
+			}
+		})
+	}
+}
+
+// balanced comprueba que las etiquetas HTML o los marcadores de MarkdownV2
+// de un texto están cerrados, con una comprobación independiente del corte
+func balanced(text, parseMode string) bool {
+	switch parseMode {
+	case ParseModeHTML:
+		var stack []string
+		for _, match := range regexp.MustCompile(`<(/?)([a-z-]+)[^>]*>`).FindAllStringSubmatch(text, -1) {
+			if match[1] == "" {
+				stack = append(stack, match[2])
+				continue
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != match[2] {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		}
+		return len(stack) == 0
+	case ParseModeMarkdownV2:
+		unescaped := regexp.MustCompile(`\\.`).ReplaceAllString(text, "")
+		blocks := strings.Count(unescaped, "```")
+		return blocks%2 == 0 && strings.Count(strings.ReplaceAll(unescaped, "```", ""), "*")%2 == 0
+	}
+	return true
+}
+
+// partsAPI Bot API que guarda los textos recibidos y falla una vez en la
+// llamada número failAt
+type partsAPI struct {
+	mu     sync.Mutex
+	calls  int
+	failAt int
+	texts  []string
+}
+
+func (f *partsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&payload)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls == f.failAt {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "description": "Too Many Requests"})
+		return
+	}
+	f.texts = append(f.texts, payload["chat_id"].(string)+":"+payload["text"].(string)[:6])
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": map[string]int{"message_id": f.calls}})
+}
+
+func TestTelegramRetryResumesParts(t *testing.T) {
+	// Tres partes por chat: "part-1", "part-2" y "part-3"
+	var message strings.Builder
+	for i := 1; i <= 3; i++ {
+		message.WriteString(fmt.Sprintf("part-%d", i) + strings.Repeat(".", telegramMaxMessageLength-7) + "\n")
+	}
+	templatePath := filepath.Join(t.TempDir(), "message.tmpl")
+	if err := os.WriteFile(templatePath, []byte(message.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Falla la segunda parte del segundo chat
+	api := &partsAPI{failAt: 5}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	notifier, err := NewTelegramNotifier(TelegramOptions{
+		BotToken:     testBotToken,
+		Chats:        []TelegramChat{{ChatID: "1"}, {ChatID: "2"}},
+		TemplatePath: templatePath,
+		ParseMode:    ParseModeNone,
+		APIURL:       server.URL,
+		Progress:     io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("NewTelegramNotifier: %v", err)
+	}
+
+	data := &model.NotificationData{}
+	if err := notifier.Notify(context.Background(), data); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if err := notifier.Notify(context.Background(), data); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	want := []string{"1:part-1", "1:part-2", "1:part-3", "2:part-1", "2:part-2", "2:part-3"}
+	assertEqual(t, api.texts, want)
+
+	// Una vez entregado, el mismo mensaje vuelve a enviarse completo
+	api.texts = nil
+	if err := notifier.Notify(context.Background(), data); err != nil {
+		t.Fatalf("new send: %v", err)
+	}
+	assertEqual(t, api.texts, want)
+}
+
+// botRequest petición recibida por la Bot API falsa, con los campos como texto
+type botRequest struct {
+	method   string
+	fields   map[string]string
+	filename string
+	document string
+}
+
+// recordingBotAPI Bot API que acepta sendMessage (JSON) y sendDocument
+// (multipart) y guarda cada petición
+type recordingBotAPI struct {
+	t        *testing.T
+	mu       sync.Mutex
+	requests []botRequest
+}
+
+func (f *recordingBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := botRequest{method: filepath.Base(r.URL.Path), fields: make(map[string]string)}
+	switch request.method {
+	case "sendMessage":
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			f.t.Errorf("invalid sendMessage payload: %v", err)
+		}
+		for key, value := range payload {
+			request.fields[key] = fmt.Sprint(value)
+		}
+	case "sendDocument":
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			f.t.Errorf("invalid sendDocument payload: %v", err)
+		}
+		for key, values := range r.MultipartForm.Value {
+			request.fields[key] = values[0]
+		}
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			f.t.Errorf("sendDocument without document: %v", err)
+		} else {
+			content, _ := io.ReadAll(file)
+			request.filename, request.document = header.Filename, string(content)
+		}
+	default:
+		f.t.Errorf("unexpected method %s", request.method)
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": map[string]int{"message_id": 1}})
+}
+
+func TestTelegramDelivery(t *testing.T) {
+	chats := []TelegramChat{
+		{ChatID: "100"},
+		{ChatID: "-200", MessageThreadID: 7, DisableNotification: true},
+	}
+	upToDate := mqttTestData()
+	upToDate.Report.UpToDate, upToDate.Report.Available = upToDate.Report.Available, nil
+
+	tests := []struct {
+		name      string
+		options   TelegramOptions
+		data      *model.NotificationData
+		method    string
+		threads   []string
+		silent    []string
+		checkBody func(t *testing.T, request botRequest)
+	}{
+		{
+			name:    "message per chat",
+			options: TelegramOptions{ParseMode: ParseModeHTML},
+			data:    mqttTestData(),
+			method:  "sendMessage",
+			threads: []string{"", "7"},
+			silent:  []string{"", "true"},
+			checkBody: func(t *testing.T, request botRequest) {
+				assertEqual(t, request.fields["parse_mode"], ParseModeHTML)
+				if !strings.Contains(request.fields["text"], "web") {
+					t.Errorf("message does not mention the container: %q", request.fields["text"])
+				}
+			},
+		},
+		{
+			name:    "silent when up to date",
+			options: TelegramOptions{ParseMode: ParseModeNone, SilentWhenUpToDate: true},
+			data:    upToDate,
+			method:  "sendMessage",
+			threads: []string{"", "7"},
+			silent:  []string{"true", "true"},
+			checkBody: func(t *testing.T, request botRequest) {
+				if _, ok := request.fields["parse_mode"]; ok {
+					t.Error("parse_mode sent without a parse mode")
+				}
+			},
+		},
+		{
+			name:    "updates are not silenced",
+			options: TelegramOptions{ParseMode: ParseModeNone, SilentWhenUpToDate: true},
+			data:    mqttTestData(),
+			method:  "sendMessage",
+			threads: []string{"", "7"},
+			silent:  []string{"", "true"},
+		},
+		{
+			name:    "below document threshold",
+			options: TelegramOptions{ParseMode: ParseModeHTML, DocumentThreshold: telegramMaxMessageLength},
+			data:    mqttTestData(),
+			method:  "sendMessage",
+			threads: []string{"", "7"},
+			silent:  []string{"", "true"},
+		},
+		{
+			name:    "document above threshold",
+			options: TelegramOptions{ParseMode: ParseModeHTML, DocumentThreshold: 10},
+			data:    mqttTestData(),
+			method:  "sendDocument",
+			threads: []string{"", "7"},
+			silent:  []string{"", "true"},
+			checkBody: func(t *testing.T, request botRequest) {
+				assertEqual(t, request.filename, "docker-image-checker-host.txt")
+				assertEqual(t, request.fields["parse_mode"], ParseModeHTML)
+				if !strings.Contains(request.fields["caption"], "host") {
+					t.Errorf("caption is not the report summary: %q", request.fields["caption"])
+				}
+				if !strings.Contains(request.document, "web") {
+					t.Errorf("document does not contain the report: %q", request.document)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &recordingBotAPI{t: t}
+			server := httptest.NewServer(api)
+			defer server.Close()
+
+			options := tt.options
+			options.BotToken, options.Chats, options.APIURL, options.Progress = testBotToken, chats, server.URL, io.Discard
+			notifier, err := NewTelegramNotifier(options)
+			if err != nil {
+				t.Fatalf("NewTelegramNotifier: %v", err)
+			}
+			if err := notifier.Notify(context.Background(), tt.data); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			if len(api.requests) != len(chats) {
+				t.Fatalf("got %d requests, want one per chat", len(api.requests))
+			}
+			for i, request := range api.requests {
+				assertEqual(t, request.method, tt.method)
+				assertEqual(t, request.fields["chat_id"], chats[i].ChatID)
+				assertEqual(t, request.fields["message_thread_id"], tt.threads[i])
+				assertEqual(t, request.fields["disable_notification"], tt.silent[i])
+				if tt.checkBody != nil {
+					tt.checkBody(t, request)
+				}
+			}
+		})
+	}
+}
+
+func TestTelegramAsDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		message   string
+		want      bool
+	}{
+		{name: "disabled", threshold: 0, message: strings.Repeat("x", 10000), want: false},
+		{name: "at threshold", threshold: 4, message: "xxxx", want: false},
+		{name: "above threshold", threshold: 4, message: "xxxxx", want: true},
+		{name: "utf-16 units", threshold: 4, message: "🔄🔄🔄", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &TelegramNotifier{options: TelegramOptions{DocumentThreshold: tt.threshold}}
+			assertEqual(t, notifier.asDocument(tt.message), tt.want)
+		})
+	}
+}
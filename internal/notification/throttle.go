@@ -0,0 +1,464 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// defaultFlushInterval cada cuánto se comprueba en modo daemon si hay lotes
+// retenidos listos para enviar
+const defaultFlushInterval = time.Minute
+
+// QuietHours franja horaria en la que solo se envían las notificaciones críticas
+type QuietHours struct {
+	// Start y End son minutos desde medianoche; si Start > End la franja cruza
+	// la medianoche (ej: 22:00-07:00)
+	Start, End int
+	Location   *time.Location
+}
+
+// ParseQuietHours interpreta una franja "HH:MM"-"HH:MM" en una zona horaria
+// IANA (vacía = hora local)
+func ParseQuietHours(start, end, timezone string) (*QuietHours, error) {
+	startMinutes, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	endMinutes, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+	if startMinutes == endMinutes {
+		return nil, fmt.Errorf("quiet hours start and end must differ")
+	}
+
+	location := time.Local
+	if timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", timezone)
+		}
+	}
+
+	return &QuietHours{Start: startMinutes, End: endMinutes, Location: location}, nil
+}
+
+// parseClock convierte "HH:MM" en minutos desde medianoche
+func parseClock(value string) (int, error) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	h, errH := strconv.Atoi(hours)
+	m, errM := strconv.Atoi(minutes)
+	if !ok || errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return h*60 + m, nil
+}
+
+// Contains indica si un instante cae dentro de la franja
+func (q *QuietHours) Contains(t time.Time) bool {
+	local := t.In(q.Location)
+	minutes := local.Hour()*60 + local.Minute()
+	if q.Start < q.End {
+		return minutes >= q.Start && minutes < q.End
+	}
+	return minutes >= q.Start || minutes < q.End
+}
+
+// EndAfter devuelve el final de la franja que contiene t
+func (q *QuietHours) EndAfter(t time.Time) time.Time {
+	local := t.In(q.Location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End/60, q.End%60, 0, 0, q.Location)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// RateLimit número máximo de notificaciones por ventana de tiempo
+type RateLimit struct {
+	Max int
+	Per time.Duration
+}
+
+// ThrottleOptions configuración del control de envío
+type ThrottleOptions struct {
+	// QuietHours retiene las notificaciones no críticas (sin fallos) y las
+	// envía juntas al terminar la franja; nil la desactiva
+	QuietHours *QuietHours
+	// RateLimits límite por nombre de notificador; lo que lo supera se
+	// acumula y se envía en un único mensaje cuando vuelve a haber cupo
+	RateLimits map[string]RateLimit
+	// CollapseFailures agrupa en un solo mensaje las notificaciones que solo
+	// contienen fallos durante esta ventana (0 = envío inmediato)
+	CollapseFailures time.Duration
+	// StateFile guarda en disco lo retenido para no perderlo entre
+	// ejecuciones (--once desde cron); vacío = solo en memoria
+	StateFile string
+	// FlushInterval frecuencia de comprobación en modo daemon
+	FlushInterval time.Duration
+	// Progress destino de los mensajes de progreso (nil = stdout)
+	Progress io.Writer
+}
+
+// throttleState lo retenido y el historial de envíos
+type throttleState struct {
+	// Quiet lote retenido por horas de silencio
+	Quiet *model.NotificationData `json:"quiet,omitempty"`
+	// Failures lote de fallos en agrupación desde FailuresSince
+	Failures      *model.NotificationData `json:"failures,omitempty"`
+	FailuresSince time.Time               `json:"failures_since,omitempty"`
+	// Deferred lotes retenidos por el límite de cada notificador
+	Deferred map[string]*model.NotificationData `json:"deferred,omitempty"`
+	// Sent instantes de los últimos envíos por notificador
+	Sent map[string][]time.Time `json:"sent,omitempty"`
+}
+
+// Throttle envuelve al NotificationManager aplicando horas de silencio,
+// límites de envío por notificador y agrupación de ráfagas de fallos
+type Throttle struct {
+	manager *NotificationManager
+	options ThrottleOptions
+
+	mu    sync.Mutex
+	state throttleState
+}
+
+// NewThrottle crea el control de envío y recupera lo retenido en StateFile
+func NewThrottle(manager *NotificationManager, options ThrottleOptions) (*Throttle, error) {
+	for name, limit := range options.RateLimits {
+		if limit.Max <= 0 || limit.Per <= 0 {
+			return nil, fmt.Errorf("invalid rate limit for %s: max and period must be positive", name)
+		}
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	options.Progress = progressOutput(options.Progress)
+
+	throttle := &Throttle{
+		manager: manager,
+		options: options,
+		state: throttleState{
+			Deferred: make(map[string]*model.NotificationData),
+			Sent:     make(map[string][]time.Time),
+		},
+	}
+	if err := throttle.load(); err != nil {
+		return nil, err
+	}
+	return throttle, nil
+}
+
+// NotifyAll envía primero los lotes retenidos que ya pueden salir y después
+// la nueva notificación, salvo que deba retenerse. Devuelve nil si queda
+// retenida: se entregará en una ejecución posterior o desde Run.
+func (t *Throttle) NotifyAll(ctx context.Context, data *model.NotificationData) error {
+	return t.notifyAll(ctx, data, time.Now())
+}
+
+// notifyAll implementa NotifyAll a la hora indicada
+func (t *Throttle) notifyAll(ctx context.Context, data *model.NotificationData, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	errs := []error{t.flush(ctx, now)}
+
+	// current es el resultado de la nueva notificación; retenerla cuenta
+	// como entrega
+	var current error
+	switch {
+	case t.options.CollapseFailures > 0 && failureOnly(data):
+		if t.state.Failures == nil {
+			t.state.FailuresSince = now
+		}
+		t.state.Failures = mergeData(t.state.Failures, data)
+		fmt.Fprintln(t.options.Progress, i18n.T("throttle.collapsing", t.state.FailuresSince.Add(t.options.CollapseFailures).Format("15:04")))
+	case t.options.QuietHours != nil && t.options.QuietHours.Contains(now) && !critical(data):
+		t.state.Quiet = mergeData(t.state.Quiet, data)
+		fmt.Fprintln(t.options.Progress, i18n.T("throttle.quiet", t.options.QuietHours.EndAfter(now).Format("15:04")))
+	default:
+		current = t.deliver(ctx, data, now, nil)
+		errs = append(errs, withoutPartial(current))
+	}
+
+	errs = append(errs, t.save())
+	return partial(errors.Join(errs...), Delivered(current))
+}
+
+// Flush envía los lotes retenidos que ya pueden salir
+func (t *Throttle) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return errors.Join(t.flush(ctx, time.Now()), t.save())
+}
+
+// Run comprueba periódicamente los lotes retenidos hasta que se cancela el contexto
+func (t *Throttle) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Printf("Throttle: failed to deliver held notifications: %v", err)
+			}
+		}
+	}
+}
+
+// flush entrega los lotes cuyo momento ha llegado; requiere t.mu
+func (t *Throttle) flush(ctx context.Context, now time.Time) error {
+	var errs []error
+
+	if t.state.Failures != nil && !now.Before(t.state.FailuresSince.Add(t.options.CollapseFailures)) {
+		data := t.state.Failures
+		t.state.Failures = nil
+		fmt.Fprintln(t.options.Progress, i18n.T("throttle.flush"))
+		errs = append(errs, withoutPartial(t.deliver(ctx, data, now, nil)))
+	}
+
+	if t.state.Quiet != nil && (t.options.QuietHours == nil || !t.options.QuietHours.Contains(now)) {
+		data := t.state.Quiet
+		t.state.Quiet = nil
+		fmt.Fprintln(t.options.Progress, i18n.T("throttle.flush"))
+		errs = append(errs, withoutPartial(t.deliver(ctx, data, now, nil)))
+	}
+
+	for name, data := range t.state.Deferred {
+		if !t.allowed(name, now) {
+			continue
+		}
+		delete(t.state.Deferred, name)
+		only := name
+		errs = append(errs, withoutPartial(t.deliver(ctx, data, now, func(n string) bool { return n == only })))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver envía a los notificadores que aceptan filter y tienen cupo, y
+// aplaza el envío para los que han superado su límite. Solo los envíos
+// correctos consumen cupo; los aplazados cuentan como entregados para
+// PartialError. Requiere t.mu.
+func (t *Throttle) deliver(ctx context.Context, data *model.NotificationData, now time.Time, filter func(string) bool) error {
+	allowed := make(map[string]bool)
+	delivered := false
+	for _, observer := range t.manager.Observers() {
+		name := ObserverName(observer)
+		if filter != nil && !filter(name) {
+			continue
+		}
+
+		if t.allowed(name, now) {
+			allowed[name] = true
+			continue
+		}
+
+		t.state.Deferred[name] = mergeData(t.state.Deferred[name], data)
+		delivered = true
+		fmt.Fprintln(t.options.Progress, i18n.T("throttle.rate_limited", name, t.nextAllowed(name).Format("15:04")))
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, result := range t.manager.notifyEach(ctx, data, func(name string) bool { return allowed[name] }) {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		delivered = true
+		if _, limited := t.options.RateLimits[result.name]; limited {
+			t.state.Sent[result.name] = append(t.state.Sent[result.name], now)
+		}
+	}
+	return partial(errors.Join(errs...), delivered)
+}
+
+// allowed indica si un notificador tiene cupo y descarta los envíos que
+// ya han salido de su ventana; requiere t.mu
+func (t *Throttle) allowed(name string, now time.Time) bool {
+	limit, ok := t.options.RateLimits[name]
+	if !ok {
+		return true
+	}
+
+	sent := t.state.Sent[name][:0]
+	for _, at := range t.state.Sent[name] {
+		if now.Sub(at) < limit.Per {
+			sent = append(sent, at)
+		}
+	}
+	t.state.Sent[name] = sent
+	return len(sent) < limit.Max
+}
+
+// nextAllowed momento en que un notificador vuelve a tener cupo; requiere t.mu
+func (t *Throttle) nextAllowed(name string) time.Time {
+	sent := t.state.Sent[name]
+	if len(sent) == 0 {
+		return time.Now()
+	}
+	return sent[0].Add(t.options.RateLimits[name].Per)
+}
+
+// load recupera el estado guardado; un fichero inexistente no es un error
+func (t *Throttle) load() error {
+	if t.options.StateFile == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(t.options.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read throttle state: %w", err)
+	}
+	if err := json.Unmarshal(content, &t.state); err != nil {
+		return fmt.Errorf("failed to parse throttle state: %w", err)
+	}
+	if t.state.Deferred == nil {
+		t.state.Deferred = make(map[string]*model.NotificationData)
+	}
+	if t.state.Sent == nil {
+		t.state.Sent = make(map[string][]time.Time)
+	}
+	return nil
+}
+
+// save guarda el estado de forma atómica; requiere t.mu
+func (t *Throttle) save() error {
+	if t.options.StateFile == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.options.StateFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create throttle state directory: %w", err)
+	}
+	content, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal throttle state: %w", err)
+	}
+
+	tmpPath := t.options.StateFile + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write throttle state: %w", err)
+	}
+	if err := os.Rename(tmpPath, t.options.StateFile); err != nil {
+		return fmt.Errorf("failed to replace throttle state: %w", err)
+	}
+	return nil
+}
+
+// critical indica si una notificación debe salir incluso en horas de
+// silencio: solo cuando trae fallos nuevos, no por fallos ya notificados que
+// siguen en el reporte
+func critical(data *model.NotificationData) bool {
+	if data.Changes != nil {
+		return len(data.Changes.NewFailures) > 0
+	}
+	return SeverityOf(data) >= SeverityFailure
+}
+
+// failureOnly indica si una notificación solo trae fallos
+func failureOnly(data *model.NotificationData) bool {
+	if data.Changes != nil {
+		return len(data.Changes.NewFailures) > 0 && len(data.Changes.NewUpdates) == 0 &&
+			len(data.Changes.Reminders) == 0 && len(data.Changes.Resolved) == 0
+	}
+	return data.Report != nil && len(data.Report.Failed) > 0 && len(data.Report.Available) == 0
+}
+
+// mergeData combina un lote retenido con una notificación posterior: el
+// reporte es el más reciente y los cambios se acumulan, descartando lo que
+// la notificación posterior da por resuelto (o vuelve a abrir)
+func mergeData(held, data *model.NotificationData) *model.NotificationData {
+	if held == nil {
+		return data
+	}
+
+	merged := &model.NotificationData{
+		Report:   data.Report,
+		Hostname: data.Hostname,
+		Changes:  data.Changes,
+	}
+	if held.Changes == nil || data.Changes == nil {
+		if merged.Changes == nil {
+			merged.Changes = held.Changes
+		}
+		return merged
+	}
+
+	resolved := containerNames(data.Changes.Resolved)
+	reopened := containerNames(data.Changes.NewUpdates, data.Changes.NewFailures)
+	merged.Changes = &model.ChangeSet{
+		NewUpdates:  without(mergeUpdates(held.Changes.NewUpdates, data.Changes.NewUpdates), resolved),
+		NewFailures: without(mergeUpdates(held.Changes.NewFailures, data.Changes.NewFailures), resolved),
+		Reminders:   without(mergeUpdates(held.Changes.Reminders, data.Changes.Reminders), resolved),
+		Resolved:    without(mergeUpdates(held.Changes.Resolved, data.Changes.Resolved), reopened),
+		Recovered:   without(mergeUpdates(held.Changes.Recovered, data.Changes.Recovered), reopened),
+		Applied:     without(mergeUpdates(held.Changes.Applied, data.Changes.Applied), reopened),
+		Removed:     without(mergeUpdates(held.Changes.Removed, data.Changes.Removed), reopened),
+	}
+	return merged
+}
+
+// mergeUpdates une dos listas por nombre de contenedor; b reemplaza a a
+func mergeUpdates(a, b []model.UpdateInfo) []model.UpdateInfo {
+	result := append([]model.UpdateInfo(nil), a...)
+	index := make(map[string]int, len(result))
+	for i, update := range result {
+		index[update.Container.Name] = i
+	}
+	for _, update := range b {
+		if i, ok := index[update.Container.Name]; ok {
+			result[i] = update
+			continue
+		}
+		index[update.Container.Name] = len(result)
+		result = append(result, update)
+	}
+	return result
+}
+
+// without descarta los contenedores indicados
+func without(updates []model.UpdateInfo, names map[string]bool) []model.UpdateInfo {
+	var result []model.UpdateInfo
+	for _, update := range updates {
+		if !names[update.Container.Name] {
+			result = append(result, update)
+		}
+	}
+	return result
+}
+
+// containerNames conjunto de nombres de contenedor de varias listas
+func containerNames(lists ...[]model.UpdateInfo) map[string]bool {
+	names := make(map[string]bool)
+	for _, list := range lists {
+		for _, update := range list {
+			names[update.Container.Name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,238 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// recordingObserver guarda las notificaciones recibidas y falla si se indica
+type recordingObserver struct {
+	mu       sync.Mutex
+	received []*model.NotificationData
+	err      error
+}
+
+func (r *recordingObserver) Notify(ctx context.Context, data *model.NotificationData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.received = append(r.received, data)
+	return nil
+}
+
+func (r *recordingObserver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.received)
+}
+
+func (r *recordingObserver) last() *model.NotificationData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received[len(r.received)-1]
+}
+
+func newTestThrottle(t *testing.T, options ThrottleOptions) (*Throttle, *recordingObserver) {
+	t.Helper()
+	observer := &recordingObserver{}
+	manager := NewNotificationManager()
+	manager.Subscribe(WithName("ops", observer))
+
+	throttle, err := NewThrottle(manager, options)
+	if err != nil {
+		t.Fatalf("NewThrottle: %v", err)
+	}
+	return throttle, observer
+}
+
+// flushAt entrega lo retenido como si fuera la hora indicada
+func flushAt(t *testing.T, throttle *Throttle, now time.Time) {
+	t.Helper()
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	if err := throttle.flush(context.Background(), now); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+}
+
+func update(name string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container:      model.Container{Name: name, ImageName: name + ":1.0.0"},
+		CurrentVersion: "1.0.0",
+		LatestVersion:  "1.0.1",
+	}
+}
+
+func failure(name string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container: model.Container{Name: name, ImageName: name + ":1.0.0"},
+		Error:     errors.New("401 Unauthorized"),
+	}
+}
+
+func TestQuietHoursAcrossMidnight(t *testing.T) {
+	quiet, err := ParseQuietHours("22:00", "07:00", "Europe/Madrid")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 1, 15, 20, 59, 0, 0, time.UTC), false}, // 21:59 CET
+		{time.Date(2024, 1, 15, 21, 0, 0, 0, time.UTC), true},   // 22:00 CET
+		{time.Date(2024, 1, 15, 23, 30, 0, 0, time.UTC), true},  // 00:30 CET
+		{time.Date(2024, 1, 16, 5, 59, 0, 0, time.UTC), true},   // 06:59 CET
+		{time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC), false},   // 07:00 CET
+		{time.Date(2024, 7, 15, 20, 0, 0, 0, time.UTC), true},   // 22:00 CEST
+		{time.Date(2024, 7, 16, 5, 0, 0, 0, time.UTC), false},   // 07:00 CEST
+	}
+	for _, tt := range tests {
+		if got := quiet.Contains(tt.at); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	// El final de la franja es siempre las 07:00 siguientes en Madrid
+	for _, at := range []time.Time{
+		time.Date(2024, 1, 15, 21, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 16, 5, 0, 0, 0, time.UTC),
+	} {
+		if got, want := quiet.EndAfter(at), time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("EndAfter(%s) = %s, want %s", at, got, want)
+		}
+	}
+}
+
+func TestThrottleQuietHoursHoldNonCritical(t *testing.T) {
+	quiet, err := ParseQuietHours("22:00", "07:00", "Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	throttle, observer := newTestThrottle(t, ThrottleOptions{QuietHours: quiet})
+	ctx := context.Background()
+	night := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC) // 00:00 en Madrid
+
+	// Un fallo ya notificado sigue en el reporte, pero no es nuevo: se retiene
+	reminder := &model.NotificationData{
+		Report:  &model.CheckReport{Available: []model.UpdateInfo{update("web")}, Failed: []model.UpdateInfo{failure("db")}},
+		Changes: &model.ChangeSet{NewUpdates: []model.UpdateInfo{update("web")}},
+	}
+	if err := throttle.notifyAll(ctx, reminder, night); err != nil {
+		t.Fatal(err)
+	}
+	if observer.count() != 0 {
+		t.Fatal("notification without new failures was sent during quiet hours")
+	}
+
+	// Un fallo nuevo se envía aunque sea de noche
+	newFailure := &model.NotificationData{
+		Report:  &model.CheckReport{Failed: []model.UpdateInfo{failure("db"), failure("api")}},
+		Changes: &model.ChangeSet{NewFailures: []model.UpdateInfo{failure("api")}},
+	}
+	if err := throttle.notifyAll(ctx, newFailure, night.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if observer.count() != 1 {
+		t.Fatalf("new failure: %d notifications sent, want 1", observer.count())
+	}
+
+	flushAt(t, throttle, night.Add(6*time.Hour)) // 06:00 en Madrid
+	if observer.count() != 1 {
+		t.Fatal("held batch sent before the end of quiet hours")
+	}
+	flushAt(t, throttle, night.Add(7*time.Hour)) // 07:00 en Madrid
+	if observer.count() != 2 {
+		t.Fatalf("held batch not sent after quiet hours: %d notifications", observer.count())
+	}
+	if held := observer.last(); len(held.Changes.NewUpdates) != 1 || held.Changes.NewUpdates[0].Container.Name != "web" {
+		t.Errorf("held batch = %+v", held.Changes)
+	}
+}
+
+func TestThrottleRateLimitExpiry(t *testing.T) {
+	throttle, observer := newTestThrottle(t, ThrottleOptions{
+		RateLimits: map[string]RateLimit{"ops": {Max: 1, Per: time.Hour}},
+	})
+	ctx := context.Background()
+	start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	notification := func(name string) *model.NotificationData {
+		return &model.NotificationData{Changes: &model.ChangeSet{NewUpdates: []model.UpdateInfo{update(name)}}}
+	}
+
+	// Un envío fallido no consume cupo
+	observer.err = errors.New("unavailable")
+	if err := throttle.notifyAll(ctx, notification("web"), start); err == nil {
+		t.Fatal("expected the delivery error")
+	}
+	observer.err = nil
+	if err := throttle.notifyAll(ctx, notification("web"), start.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if observer.count() != 1 {
+		t.Fatalf("%d notifications sent, want 1", observer.count())
+	}
+
+	// Lo que supera el límite se acumula hasta que expira la ventana
+	for _, name := range []string{"db", "api"} {
+		if err := throttle.notifyAll(ctx, notification(name), start.Add(10*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flushAt(t, throttle, start.Add(time.Hour))
+	if observer.count() != 1 {
+		t.Fatal("deferred batch sent before the rate limit window expired")
+	}
+	flushAt(t, throttle, start.Add(time.Hour+time.Minute))
+	if observer.count() != 2 {
+		t.Fatalf("deferred batch not sent after the window: %d notifications", observer.count())
+	}
+	if got := observer.last().Changes.NewUpdates; len(got) != 2 {
+		t.Errorf("deferred batch has %d updates, want db and api", len(got))
+	}
+}
+
+func TestThrottleCollapseFailures(t *testing.T) {
+	throttle, observer := newTestThrottle(t, ThrottleOptions{CollapseFailures: 10 * time.Minute})
+	ctx := context.Background()
+	start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for i, name := range []string{"db", "api"} {
+		data := &model.NotificationData{
+			Report:  &model.CheckReport{Failed: []model.UpdateInfo{failure(name)}},
+			Changes: &model.ChangeSet{NewFailures: []model.UpdateInfo{failure(name)}},
+		}
+		if err := throttle.notifyAll(ctx, data, start.Add(time.Duration(i)*5*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Las actualizaciones no se agrupan
+	updates := &model.NotificationData{Changes: &model.ChangeSet{NewUpdates: []model.UpdateInfo{update("web")}}}
+	if err := throttle.notifyAll(ctx, updates, start.Add(6*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if observer.count() != 1 {
+		t.Fatalf("%d notifications sent during the collapse window, want only the updates", observer.count())
+	}
+
+	flushAt(t, throttle, start.Add(9*time.Minute))
+	if observer.count() != 1 {
+		t.Fatal("failures sent before the collapse window ended")
+	}
+	flushAt(t, throttle, start.Add(10*time.Minute))
+	if observer.count() != 2 {
+		t.Fatalf("collapsed failures not sent: %d notifications", observer.count())
+	}
+	if got := observer.last().Changes.NewFailures; len(got) != 2 {
+		t.Errorf("collapsed batch has %d failures, want 2", len(got))
+	}
+}
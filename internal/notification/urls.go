@@ -0,0 +1,482 @@
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// Factory crea un Observer a partir de una URL de servicio ya parseada
+type Factory func(u *url.URL) (Observer, error)
+
+// factories registro de factorías indexado por esquema de URL
+var factories = map[string]Factory{
+	"telegram": telegramFromURL,
+	"ntfy":     ntfyFromURL,
+	"gotify":   gotifyFromURL,
+	"matrix":   matrixFromURL,
+	"slack":    slackFromURL,
+	"discord":  discordFromURL,
+	"smtp":     smtpFromURL,
+	"generic":  genericFromURL,
+	"mqtt":     mqttFromURL,
+	"mqtts":    mqttFromURL,
+	"syslog":   syslogFromURL,
+}
+
+// factoriesMu protege factories, que RegisterFactory puede modificar en
+// cualquier momento
+var factoriesMu sync.RWMutex
+
+// RegisterFactory registra (o reemplaza) la factoría de un esquema de URL.
+// Es seguro para uso concurrente con NewFromURL.
+func RegisterFactory(scheme string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[strings.ToLower(scheme)] = factory
+}
+
+// ServiceName devuelve el servicio de una URL de notificación (ej: "slack"),
+// usado como nombre por defecto del notificador
+func ServiceName(rawURL string) string {
+	scheme, _, _ := strings.Cut(rawURL, "://")
+	service, _, _ := strings.Cut(strings.ToLower(scheme), "+")
+	return service
+}
+
+// NewFromURL crea el Observer correspondiente a una URL de servicio
+// (ej: "telegram://token@telegram?chats=123"). Las variables de entorno
+// con formato $VAR o ${VAR} se expanden en cada componente de la URL y
+// "$$" representa un "$" literal.
+func NewFromURL(rawURL string) (Observer, error) {
+	u, err := url.Parse(expandURL(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification URL: %w", err)
+	}
+
+	// Los esquemas compuestos como "generic+https" se resuelven por su prefijo
+	service := ServiceName(u.Scheme + "://")
+	factoriesMu.RLock()
+	factory, ok := factories[service]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported notification service %q", u.Scheme)
+	}
+
+	observer, err := factory(u)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s notification URL: %w", service, err)
+	}
+	return observer, nil
+}
+
+// expandURL expande las variables de entorno de una URL de servicio
+// escapando su valor según el componente en el que aparecen, para que los
+// secretos con caracteres reservados (@ / ? # % :) no alteren la URL
+func expandURL(rawURL string) string {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return expandEnv(rawURL, func(value string) string { return value })
+	}
+	rest, fragment, hasFragment := strings.Cut(rest, "#")
+	rest, query, hasQuery := strings.Cut(rest, "?")
+	authority, path := rest, ""
+	if i := strings.Index(rest, "/"); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+	userinfo, host, hasUserinfo := "", authority, false
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo, host, hasUserinfo = authority[:i], authority[i+1:], true
+	}
+
+	var b strings.Builder
+	b.WriteString(scheme + "://")
+	if hasUserinfo {
+		b.WriteString(expandEnv(userinfo, escapeUserinfo) + "@")
+	}
+	b.WriteString(expandEnv(host, func(value string) string { return value }))
+	b.WriteString(expandEnv(path, url.PathEscape))
+	if hasQuery {
+		b.WriteString("?" + expandEnv(query, url.QueryEscape))
+	}
+	if hasFragment {
+		b.WriteString("#" + expandEnv(fragment, func(value string) string { return value }))
+	}
+	return b.String()
+}
+
+// expandEnv sustituye $VAR y ${VAR} por el valor escapado de la variable,
+// y "$$" por un "$" literal
+func expandEnv(s string, escape func(string) string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return escape(os.Getenv(name))
+	})
+}
+
+// escapeUserinfo escapa todo salvo los caracteres no reservados y ":", de
+// modo que "${USER}:${PASS}" y un token "id:secreto" siguen separándose
+// por el primer ":"
+func escapeUserinfo(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == ':' || c == '-' || c == '.' || c == '_' || c == '~' ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// telegramFromURL telegram://<token>@telegram?chats=<id>[:<thread>][,...]&parsemode=&silent=&document=
+func telegramFromURL(u *url.URL) (Observer, error) {
+	if u.User == nil {
+		return nil, fmt.Errorf("missing bot token")
+	}
+	token := userToken(u.User)
+	query := u.Query()
+
+	parseMode, err := ParseTelegramParseMode(query.Get("parsemode"))
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := intParam(query, "document")
+	if err != nil {
+		return nil, err
+	}
+
+	options := TelegramOptions{
+		BotToken:          token,
+		TemplatePath:      query.Get("template"),
+		EventTemplates:    eventTemplateParams(query),
+		ParseMode:         parseMode,
+		DocumentThreshold: threshold,
+	}
+	for _, value := range splitList(query.Get("chats")) {
+		chat, err := ParseTelegramChat(value)
+		if err != nil {
+			return nil, err
+		}
+		chat.DisableNotification = boolParam(query, "silent")
+		options.Chats = append(options.Chats, chat)
+	}
+	if len(options.Chats) == 0 {
+		return nil, fmt.Errorf("missing chats parameter")
+	}
+
+	return NewTelegramNotifier(options)
+}
+
+// ntfyFromURL ntfy://[:<token>@]<host>/<topic>?priority=&tags=&click=
+func ntfyFromURL(u *url.URL) (Observer, error) {
+	query := u.Query()
+
+	priority, err := intParam(query, "priority")
+	if err != nil {
+		return nil, err
+	}
+
+	options := NtfyOptions{
+		TopicURL:       httpScheme(query) + "://" + u.Host + u.EscapedPath(),
+		Priority:       priority,
+		Tags:           splitList(query.Get("tags")),
+		Click:          query.Get("click"),
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	}
+	if u.User != nil {
+		options.Token, _ = u.User.Password()
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("missing topic")
+	}
+
+	return NewNtfyNotifier(options)
+}
+
+// gotifyFromURL gotify://<host>[/<path>]/<token>?priority=&markdown=
+func gotifyFromURL(u *url.URL) (Observer, error) {
+	query := u.Query()
+
+	priority, err := intParam(query, "priority")
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || path[i+1:] == "" {
+		return nil, fmt.Errorf("missing app token")
+	}
+	token, err := url.PathUnescape(path[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid app token: %w", err)
+	}
+
+	return NewGotifyNotifier(GotifyOptions{
+		ServerURL:      httpScheme(query) + "://" + u.Host + path[:i],
+		AppToken:       token,
+		Priority:       priority,
+		Markdown:       boolParam(query, "markdown"),
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	})
+}
+
+// matrixFromURL matrix://:<access-token>@<host>?room=<room-id>
+func matrixFromURL(u *url.URL) (Observer, error) {
+	if u.User == nil {
+		return nil, fmt.Errorf("missing access token")
+	}
+	token, _ := u.User.Password()
+	query := u.Query()
+
+	return NewMatrixNotifier(MatrixOptions{
+		HomeserverURL:  httpScheme(query) + "://" + u.Host + strings.TrimRight(u.EscapedPath(), "/"),
+		RoomID:         query.Get("room"),
+		AccessToken:    token,
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	})
+}
+
+// slackFromURL slack://<tokenA>/<tokenB>/<tokenC>?channel=&username=
+func slackFromURL(u *url.URL) (Observer, error) {
+	// Los tokens se separan en la ruta escapada para que un "/" escapado
+	// dentro de un token no cuente como separador
+	tokens := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	if u.Host == "" || len(tokens) != 2 {
+		return nil, fmt.Errorf("expected slack://tokenA/tokenB/tokenC")
+	}
+	for i, token := range tokens {
+		decoded, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token %q: %w", token, err)
+		}
+		tokens[i] = decoded
+	}
+	query := u.Query()
+
+	return NewSlackNotifier(SlackOptions{
+		WebhookURL:     joinURL("https://hooks.slack.com/services", u.Host, tokens[0], tokens[1]),
+		Channel:        query.Get("channel"),
+		Username:       query.Get("username"),
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	})
+}
+
+// discordFromURL discord://<token>@<webhook-id>?username=
+func discordFromURL(u *url.URL) (Observer, error) {
+	if u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("expected discord://token@webhookid")
+	}
+	query := u.Query()
+
+	return NewDiscordNotifier(DiscordOptions{
+		WebhookURL:     joinURL("https://discord.com/api/webhooks", u.Host, userToken(u.User)),
+		Username:       query.Get("username"),
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	})
+}
+
+// smtpFromURL smtp://[<user>:<password>@]<host>[:<port>]/?from=&to=[,<to>]&subject=
+func smtpFromURL(u *url.URL) (Observer, error) {
+	query := u.Query()
+
+	options := EmailOptions{
+		Host:           u.Hostname(),
+		From:           query.Get("from"),
+		To:             splitList(query.Get("to")),
+		Subject:        query.Get("subject"),
+		TemplatePath:   query.Get("template"),
+		EventTemplates: eventTemplateParams(query),
+	}
+	if port := u.Port(); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", port)
+		}
+		options.Port = p
+	}
+	if u.User != nil {
+		options.Username = u.User.Username()
+		options.Password, _ = u.User.Password()
+	}
+
+	return NewEmailNotifier(options)
+}
+
+// genericFromURL generic+https://<host>/<path>?@<Header>=<value>
+// Los parámetros con prefijo "@" se envían como cabeceras y el resto se
+// mantienen en la URL de destino, salvo "template" y "template_<evento>".
+func genericFromURL(u *url.URL) (Observer, error) {
+	_, scheme, ok := strings.Cut(strings.ToLower(u.Scheme), "+")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return nil, fmt.Errorf("expected generic+http:// or generic+https://")
+	}
+
+	query := u.Query()
+	headers := make(map[string]string)
+	for key, values := range query {
+		if strings.HasPrefix(key, "@") && len(values) > 0 {
+			headers[strings.TrimPrefix(key, "@")] = values[0]
+			query.Del(key)
+		}
+	}
+	templatePath := query.Get("template")
+	events := eventTemplateParams(query)
+	query.Del("template")
+	for _, event := range model.EventTypes {
+		query.Del("template_" + string(event))
+	}
+
+	target := *u
+	target.Scheme = scheme
+	target.RawQuery = query.Encode()
+
+	return NewWebhookNotifier(WebhookOptions{
+		URL:            target.String(),
+		Headers:        headers,
+		TemplatePath:   templatePath,
+		EventTemplates: events,
+	})
+}
+
+// mqttFromURL mqtt[s]://[<user>:<password>@]<host>[:<port>][/<topic-prefix>]?discovery=&discovery_prefix=&qos=&client_id=&ca=&cert=&key=&insecure=
+func mqttFromURL(u *url.URL) (Observer, error) {
+	query := u.Query()
+
+	qos, err := intParam(query, "qos")
+	if err != nil {
+		return nil, err
+	}
+
+	options := MQTTOptions{
+		BrokerURL:          u.Scheme + "://" + u.Host,
+		ClientID:           query.Get("client_id"),
+		TopicPrefix:        strings.Trim(u.Path, "/"),
+		QoS:                qos,
+		Discovery:          boolParam(query, "discovery"),
+		DiscoveryPrefix:    query.Get("discovery_prefix"),
+		CAFile:             query.Get("ca"),
+		CertFile:           query.Get("cert"),
+		KeyFile:            query.Get("key"),
+		InsecureSkipVerify: boolParam(query, "insecure"),
+	}
+	if u.User != nil {
+		options.Username = u.User.Username()
+		options.Password, _ = u.User.Password()
+	}
+
+	return NewMQTTNotifier(options)
+}
+
+// syslogFromURL syslog://, syslog+udp://<host>[:<port>], syslog+tcp://<host>[:<port>]
+// o syslog+unix://<socket-path>, con ?facility=&app=
+func syslogFromURL(u *url.URL) (Observer, error) {
+	query := u.Query()
+	options := SyslogOptions{
+		Facility: query.Get("facility"),
+		AppName:  query.Get("app"),
+	}
+
+	_, network, _ := strings.Cut(strings.ToLower(u.Scheme), "+")
+	switch network {
+	case "":
+	case "udp", "tcp":
+		options.Network, options.Address = network, u.Host
+	case "unix":
+		options.Network, options.Address = network, u.Host+u.Path
+	default:
+		return nil, fmt.Errorf("expected syslog://, syslog+udp://, syslog+tcp:// or syslog+unix://")
+	}
+
+	return NewSyslogNotifier(options)
+}
+
+// userToken reconstruye un token completo de la parte de usuario de la URL,
+// que url.Parse separa por el primer ":"
+func userToken(user *url.Userinfo) string {
+	token := user.Username()
+	if password, ok := user.Password(); ok {
+		token += ":" + password
+	}
+	return token
+}
+
+// joinURL añade a base los segmentos de ruta indicados, escapando cada uno
+// para que los caracteres reservados de un token (% ? # /) no alteren la URL
+func joinURL(base string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, segment := range segments {
+		b.WriteString("/" + url.PathEscape(segment))
+	}
+	return b.String()
+}
+
+// eventTemplateParams lee las plantillas por evento (template_updates,
+// template_failures, template_recovery)
+func eventTemplateParams(query url.Values) map[model.EventType]string {
+	events := make(map[model.EventType]string)
+	for _, event := range model.EventTypes {
+		if path := query.Get("template_" + string(event)); path != "" {
+			events[event] = path
+		}
+	}
+	return events
+}
+
+// httpScheme devuelve "http" si la URL pide desactivar TLS con scheme=http
+func httpScheme(query url.Values) string {
+	if strings.EqualFold(query.Get("scheme"), "http") {
+		return "http"
+	}
+	return "https"
+}
+
+// splitList separa un parámetro con valores separados por comas
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// intParam lee un parámetro numérico opcional
+func intParam(query url.Values, key string) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return n, nil
+}
+
+// boolParam lee un parámetro booleano opcional (yes/true/1)
+func boolParam(query url.Values, key string) bool {
+	switch strings.ToLower(query.Get(key)) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"github.com/pablopin/docker-image-checker/internal/templates"
+)
+
+// WebhookOptions configuración del notificador genérico por webhook
+type WebhookOptions struct {
+	URL          string
+	Headers      map[string]string
+	TemplatePath string
+	// EventTemplates plantilla por tipo de evento; tiene prioridad sobre TemplatePath
+	EventTemplates map[model.EventType]string
+}
+
+// WebhookNotifier implementa Observer enviando un JSON a un endpoint HTTP arbitrario
+type WebhookNotifier struct {
+	options   WebhookOptions
+	templates *templates.Set
+	client    *http.Client
+}
+
+// webhookPayload cuerpo JSON enviado por el notificador genérico
+type webhookPayload struct {
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	Hostname  string `json:"hostname"`
+	Total     int    `json:"total"`
+	Available int    `json:"available"`
+	Failed    int    `json:"failed"`
+}
+
+// NewWebhookNotifier crea un nuevo notificador genérico por webhook
+func NewWebhookNotifier(options WebhookOptions) (*WebhookNotifier, error) {
+	if options.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+
+	tmpl, err := templates.Load(templates.Options{
+		Name:   "webhook",
+		Path:   options.TemplatePath,
+		Events: options.EventTemplates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook template: %w", err)
+	}
+
+	return &WebhookNotifier{
+		options:   options,
+		templates: tmpl,
+		client:    newHTTPClient(),
+	}, nil
+}
+
+// Notify implementa la interfaz Observer
+func (wn *WebhookNotifier) Notify(ctx context.Context, data *model.NotificationData) error {
+	_, jsonPayload, err := wn.build(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wn.options.URL, bytes.NewReader(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range wn.options.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("webhook", resp)
+}
+
+// Preview implementa la interfaz Previewer
+func (wn *WebhookNotifier) Preview(data *model.NotificationData) (*Preview, error) {
+	message, jsonPayload, err := wn.build(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Message: message, Payloads: [][]byte{jsonPayload}}, nil
+}
+
+// build genera el mensaje y el cuerpo JSON de la petición
+func (wn *WebhookNotifier) build(data *model.NotificationData) (string, []byte, error) {
+	message, err := wn.templates.Render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate message: %w", err)
+	}
+
+	payload := webhookPayload{
+		Title:    messageTitle(data),
+		Message:  message,
+		Hostname: data.Hostname,
+	}
+	if data.Report != nil {
+		payload.Total = data.Report.Total
+		payload.Available = len(data.Report.Available)
+		payload.Failed = len(data.Report.Failed)
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return message, jsonPayload, nil
+}
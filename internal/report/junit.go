@@ -0,0 +1,102 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// junitTestSuites raíz del XML de JUnit
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite una suite por host
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Hostname  string          `xml:"hostname,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase un caso por contenedor
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure imagen desactualizada o verificación fallida
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit escribe un testcase por contenedor; las imágenes con
+// actualización y las que no se pudieron verificar se marcan como fallidas
+func writeJUnit(w io.Writer, report *model.CheckReport) error {
+	doc := NewDocument(report)
+	suite := junitTestSuite{
+		Name:      "docker-image-checker." + doc.Hostname,
+		Hostname:  doc.Hostname,
+		Timestamp: report.Timestamp.Format("2006-01-02T15:04:05"),
+		Tests:     len(doc.Containers),
+	}
+
+	for _, c := range doc.Containers {
+		testCase := junitTestCase{
+			Name:      fmt.Sprintf("%s (%s)", c.Name, c.Image),
+			ClassName: suite.Name,
+			SystemOut: fmt.Sprintf("image: %s\nregistry: %s\ncurrent_version: %s\nlatest_version: %s\nstatus: %s\n",
+				c.Image, c.Registry, c.CurrentVersion, c.LatestVersion, c.Status),
+		}
+		switch c.Status {
+		case model.StatusAvailable:
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("update available: %s -> %s (%s)", c.CurrentVersion, c.LatestVersion, c.Bump),
+				Type:    "outdated",
+				Text:    fmt.Sprintf("%s runs %s, %s is available", c.Name, c.Image, c.LatestVersion),
+			}
+		case model.StatusFailed:
+			testCase.Failure = &junitFailure{
+				Message: "check failed: " + c.Error,
+				Type:    "check_failed",
+				Text:    c.Error,
+			}
+		}
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Name:     "docker-image-checker",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,19 @@
+package report
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestJUnitGolden(t *testing.T) {
+	got := render(t, FormatJUnit, WriteOptions{})
+	assertGolden(t, "report.junit.xml", got)
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(got, &suites); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if suites.Tests != 4 || suites.Failures != 3 {
+		t.Errorf("tests=%d failures=%d, want 4 and 3", suites.Tests, suites.Failures)
+	}
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"embed"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+//go:embed pages/*.tmpl
+var pageFiles embed.FS
+
+// pageFuncs funciones comunes a las páginas HTML y Markdown
+var pageFuncs = map[string]interface{}{
+	"t":           i18n.T,
+	"statusOrder": statusOrder,
+	"bumpOrder":   bumpOrder,
+	"statusIcon":  statusIcon,
+	"md":          markdownEscape,
+	"code":        markdownCode,
+}
+
+var (
+	htmlPage = htmltemplate.Must(htmltemplate.New("report.html.tmpl").
+			Funcs(htmltemplate.FuncMap(pageFuncs)).ParseFS(pageFiles, "pages/report.html.tmpl"))
+	markdownPage = texttemplate.Must(texttemplate.New("report.md.tmpl").
+			Funcs(texttemplate.FuncMap(pageFuncs)).ParseFS(pageFiles, "pages/report.md.tmpl"))
+)
+
+// pageData datos de las plantillas de página
+type pageData struct {
+	Language string
+	Doc      *Document
+}
+
+// writeHTML escribe una página HTML autocontenida (estilos y ordenación de
+// la tabla en línea, sin recursos externos)
+func writeHTML(w io.Writer, report *model.CheckReport) error {
+	return htmlPage.Execute(w, pageData{Language: i18n.Language(), Doc: NewDocument(report)})
+}
+
+// writeMarkdown escribe el reporte como Markdown con tablas (GFM)
+func writeMarkdown(w io.Writer, report *model.CheckReport) error {
+	return markdownPage.Execute(w, pageData{Language: i18n.Language(), Doc: NewDocument(report)})
+}
+
+// statusOrder clave de ordenación del estado: primero lo que requiere atención
+func statusOrder(status model.UpdateStatus) int {
+	switch status {
+	case model.StatusFailed:
+		return 0
+	case model.StatusAvailable:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// bumpOrder clave de ordenación del salto de versión, de mayor a menor
+func bumpOrder(bump model.BumpLevel) int {
+	switch bump {
+	case model.BumpMajor:
+		return 0
+	case model.BumpMinor:
+		return 1
+	case model.BumpPatch:
+		return 2
+	case model.BumpUnknown:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// statusIcon emoji del estado para las tablas Markdown
+func statusIcon(status model.UpdateStatus) string {
+	switch status {
+	case model.StatusFailed:
+		return "❌"
+	case model.StatusAvailable:
+		return "🔄"
+	default:
+		return "✅"
+	}
+}
+
+// markdownEscape escapa el texto de una celda de tabla Markdown
+func markdownEscape(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+		"<", "&lt;", ">", "&gt;", "\r\n", " ", "\n", " ",
+	)
+	return replacer.Replace(text)
+}
+
+// markdownCode formatea un valor como código en una celda de tabla Markdown
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	text = strings.NewReplacer("`", "'", "|", `\|`, "\r\n", " ", "\n", " ").Replace(text)
+	return "`" + text + "`"
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+)
+
+// useLanguage fija el idioma de las páginas durante un test
+func useLanguage(t *testing.T, lang string) {
+	t.Helper()
+	previous := i18n.Language()
+	if err := i18n.SetLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { i18n.SetLanguage(previous) })
+}
+
+func TestPageGolden(t *testing.T) {
+	useLanguage(t, "en")
+	assertGolden(t, "report.html", render(t, FormatHTML, WriteOptions{}))
+	assertGolden(t, "report.md", render(t, FormatMarkdown, WriteOptions{}))
+}
+
+func TestPageEscaping(t *testing.T) {
+	useLanguage(t, "en")
+	report := testReport()
+	report.Hostname = "<host>"
+	report.Available[0].Container.Name = `<script>alert("web")</script>`
+	report.Available[1].Container.Name = "api|v2 *beta* `x`\nnext"
+
+	var html bytes.Buffer
+	if err := Write(&html, FormatHTML, report, WriteOptions{}); err != nil {
+		t.Fatalf("Write(html): %v", err)
+	}
+	for _, want := range []string{
+		"<td>&lt;script&gt;alert(&#34;web&#34;)&lt;/script&gt;</td>",
+		"&lt;host&gt;",
+		`<span class="error">registry unavailable: &#34;503&#34; &lt;retry&gt;</span>`,
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML page does not contain %s", want)
+		}
+	}
+	if strings.Contains(html.String(), "<script>alert") {
+		t.Error("HTML page contains an unescaped container name")
+	}
+
+	var markdown bytes.Buffer
+	if err := Write(&markdown, FormatMarkdown, report, WriteOptions{}); err != nil {
+		t.Fatalf("Write(markdown): %v", err)
+	}
+	for _, want := range []string{
+		"| api\\|v2 \\*beta\\* \\`x\\` next |",
+		"| &lt;script&gt;alert(\"web\")&lt;/script&gt; |",
+		"| registry unavailable: \"503\" &lt;retry&gt; |",
+	} {
+		if !strings.Contains(markdown.String(), want) {
+			t.Errorf("Markdown page does not contain %s:\n%s", want, markdown.String())
+		}
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "empty", text: "", want: ""},
+		{name: "image", text: "nginx:1.25", want: "`nginx:1.25`"},
+		{name: "backtick and pipe", text: "a`b|c", want: "`a'b\\|c`"},
+		{name: "newlines", text: "a\r\nb\nc", want: "`a b c`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownCode(tt.text); got != tt.want {
+				t.Errorf("markdownCode(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
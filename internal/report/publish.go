@@ -0,0 +1,144 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	// archivePrefix prefijo de los reportes archivados, seguido de la fecha UTC
+	archivePrefix = "report-"
+	archiveLayout = "20060102T150405Z"
+	// latestName nombre estable que apunta al último reporte
+	latestName = "latest"
+)
+
+// PublishOptions configuración de la publicación de reportes en un directorio
+type PublishOptions struct {
+	// Dir directorio raíz; cada host escribe en su propio subdirectorio
+	Dir string
+	// Formats formatos que se publican (por defecto html y markdown)
+	Formats []Format
+	// MaxBackups reportes archivados que se conservan por formato (0 = todos)
+	MaxBackups int
+	// Write ajustes de los formatos publicados
+	Write WriteOptions
+}
+
+// Publisher escribe tras cada verificación un reporte archivado con fecha y
+// actualiza el enlace latest al más reciente
+type Publisher struct {
+	options PublishOptions
+}
+
+// NewPublisher crea un publicador de reportes
+func NewPublisher(options PublishOptions) (*Publisher, error) {
+	if options.Dir == "" {
+		return nil, fmt.Errorf("report directory is required")
+	}
+	if len(options.Formats) == 0 {
+		options.Formats = []Format{FormatHTML, FormatMarkdown}
+	}
+	if options.MaxBackups < 0 {
+		options.MaxBackups = 0
+	}
+	return &Publisher{options: options}, nil
+}
+
+// Publish escribe el reporte en todos los formatos configurados en
+// <dir>/<host>/report-<fecha>.<ext>, apunta latest.<ext> a él y elimina los
+// archivados que superen MaxBackups
+func (p *Publisher) Publish(report *model.CheckReport) error {
+	dir := filepath.Join(p.options.Dir, hostDir(report.Hostname))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	var errs []string
+	for _, format := range p.options.Formats {
+		if err := p.publish(dir, format, report); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", format, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to publish reports: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// publish escribe el archivado de un formato, actualiza latest y poda
+func (p *Publisher) publish(dir string, format Format, report *model.CheckReport) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, format, report, p.options.Write); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	ext := "." + format.Extension()
+	archive := archivePrefix + report.Timestamp.UTC().Format(archiveLayout) + ext
+	if err := writeFileAtomic(filepath.Join(dir, archive), buf.Bytes()); err != nil {
+		return err
+	}
+	if err := linkLatest(dir, latestName+ext, archive, buf.Bytes()); err != nil {
+		return err
+	}
+	return p.prune(dir, ext)
+}
+
+// linkLatest reemplaza latest.<ext> por un enlace simbólico relativo al
+// archivado; si el sistema no admite enlaces se escribe una copia
+func linkLatest(dir, name, target string, data []byte) error {
+	path := filepath.Join(dir, name)
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return writeFileAtomic(path, data)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to update %s: %w", name, err)
+	}
+	return nil
+}
+
+// prune elimina los archivados más antiguos de una extensión por encima de MaxBackups
+func (p *Publisher) prune(dir, ext string) error {
+	if p.options.MaxBackups == 0 {
+		return nil
+	}
+	archives, err := filepath.Glob(filepath.Join(dir, archivePrefix+"*"+ext))
+	if err != nil {
+		return err
+	}
+	if len(archives) <= p.options.MaxBackups {
+		return nil
+	}
+	// La fecha del nombre ordena los archivados cronológicamente
+	sort.Strings(archives)
+	for _, path := range archives[:len(archives)-p.options.MaxBackups] {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old report: %w", err)
+		}
+	}
+	return nil
+}
+
+// hostDir nombre de directorio seguro para un hostname
+func hostDir(hostname string) string {
+	if hostname == "" || hostname == "." || hostname == ".." {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, hostname)
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// publishAt publica el reporte de prueba con la fecha indicada
+func publishAt(t *testing.T, publisher *Publisher, hostname string, timestamp time.Time) {
+	t.Helper()
+	report := testReport()
+	report.Hostname = hostname
+	report.Timestamp = timestamp
+	if err := publisher.Publish(report); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+// listDir nombres de los ficheros de un directorio, ordenados
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPublishRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		want       []string
+	}{
+		{
+			name:       "max backups",
+			maxBackups: 2,
+			want: []string{
+				"latest.html", "latest.md",
+				"report-20240301T140000Z.html", "report-20240301T140000Z.md",
+				"report-20240301T150000Z.html", "report-20240301T150000Z.md",
+			},
+		},
+		{
+			name:       "unlimited",
+			maxBackups: 0,
+			want: []string{
+				"latest.html", "latest.md",
+				"report-20240301T120000Z.html", "report-20240301T120000Z.md",
+				"report-20240301T130000Z.html", "report-20240301T130000Z.md",
+				"report-20240301T140000Z.html", "report-20240301T140000Z.md",
+				"report-20240301T150000Z.html", "report-20240301T150000Z.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			publisher, err := NewPublisher(PublishOptions{Dir: root, MaxBackups: tt.maxBackups})
+			if err != nil {
+				t.Fatalf("NewPublisher: %v", err)
+			}
+
+			start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+			for i := 0; i < 4; i++ {
+				publishAt(t, publisher, "host1", start.Add(time.Duration(i)*time.Hour))
+			}
+
+			dir := filepath.Join(root, "host1")
+			if got := listDir(t, dir); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishLatest(t *testing.T) {
+	root := t.TempDir()
+	publisher, err := NewPublisher(PublishOptions{Dir: root, Formats: []Format{FormatJSON}, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+
+	first := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	publishAt(t, publisher, "host1", first)
+	publishAt(t, publisher, "host1", first.Add(time.Hour))
+
+	dir := filepath.Join(root, "host1")
+	latest := filepath.Join(dir, "latest.json")
+	newest := "report-20240301T130000Z.json"
+
+	// latest es un enlace relativo al último archivado, o una copia si el
+	// sistema no admite enlaces
+	if target, err := os.Readlink(latest); err == nil {
+		if target != newest {
+			t.Errorf("latest.json points to %s, want %s", target, newest)
+		}
+	}
+	got, err := os.ReadFile(latest)
+	if err != nil {
+		t.Fatalf("latest.json is not readable after pruning: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, newest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Error("latest.json does not match the newest report")
+	}
+
+	if _, err := os.Stat(latest + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary link left behind: %v", err)
+	}
+}
+
+func TestPublishHostDir(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{hostname: "host1", want: "host1"},
+		{hostname: "web.example.com", want: "web.example.com"},
+		{hostname: "../etc", want: ".._etc"},
+		{hostname: "a b/c", want: "a_b_c"},
+		{hostname: "..", want: "unknown"},
+		{hostname: "", want: "unknown"},
+	}
+
+	root := t.TempDir()
+	publisher, err := NewPublisher(PublishOptions{Dir: root, Formats: []Format{FormatCSV}})
+	if err != nil {
+		t.Fatalf("NewPublisher: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			if got := hostDir(tt.hostname); got != tt.want {
+				t.Errorf("hostDir(%q) = %q, want %q", tt.hostname, got, tt.want)
+			}
+			publishAt(t, publisher, tt.hostname, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+			if _, err := os.Stat(filepath.Join(root, tt.want, "latest.csv")); err != nil {
+				t.Errorf("report not published inside the root directory: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewPublisherRequiresDir(t *testing.T) {
+	if _, err := NewPublisher(PublishOptions{}); err == nil {
+		t.Error("expected an error without a directory")
+	}
+}
@@ -0,0 +1,230 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion versión de la serialización del reporte (JSON, YAML y CSV);
+// se incrementa solo con cambios incompatibles
+const SchemaVersion = 1
+
+// Format formato de salida del reporte
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+	// FormatJUnit XML de JUnit para los informes de tests de CI
+	FormatJUnit Format = "junit"
+	// FormatSARIF SARIF 2.1.0 para las vistas de code scanning
+	FormatSARIF Format = "sarif"
+	// FormatHTML página HTML autocontenida con tabla ordenable
+	FormatHTML Format = "html"
+	// FormatMarkdown tablas Markdown (GFM)
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lista los formatos admitidos
+var Formats = []Format{FormatText, FormatJSON, FormatYAML, FormatCSV, FormatJUnit, FormatSARIF, FormatHTML, FormatMarkdown}
+
+// ParseFormat valida el nombre de un formato
+func ParseFormat(value string) (Format, error) {
+	for _, format := range Formats {
+		if Format(value) == format {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (expected text, json, yaml, csv, junit, sarif, html or markdown)", value)
+}
+
+// Extension extensión de fichero del formato, sin punto
+func (f Format) Extension() string {
+	switch f {
+	case FormatText:
+		return "txt"
+	case FormatJUnit:
+		return "xml"
+	case FormatMarkdown:
+		return "md"
+	default:
+		return string(f)
+	}
+}
+
+// WriteOptions ajustes de los formatos que dependen del entorno
+type WriteOptions struct {
+	// SARIFBaseDir directorio del repositorio con los ficheros de Compose; las
+	// ubicaciones SARIF se emiten relativas a él (vacío = solo ubicaciones lógicas)
+	SARIFBaseDir string
+}
+
+// Document serialización estable de model.CheckReport
+type Document struct {
+	SchemaVersion int         `json:"schema_version" yaml:"schema_version"`
+	Hostname      string      `json:"hostname" yaml:"hostname"`
+	Timestamp     string      `json:"timestamp" yaml:"timestamp"`
+	Summary       Summary     `json:"summary" yaml:"summary"`
+	Containers    []Container `json:"containers" yaml:"containers"`
+}
+
+// Summary contadores del reporte
+type Summary struct {
+	Total     int `json:"total" yaml:"total"`
+	Available int `json:"available" yaml:"available"`
+	Failed    int `json:"failed" yaml:"failed"`
+	UpToDate  int `json:"up_to_date" yaml:"up_to_date"`
+}
+
+// Container resultado de un contenedor. Bump solo se indica si hay
+// actualización y Error solo si la verificación falló.
+type Container struct {
+	Name           string             `json:"name" yaml:"name"`
+	ID             string             `json:"id" yaml:"id"`
+	Image          string             `json:"image" yaml:"image"`
+	Registry       string             `json:"registry" yaml:"registry"`
+	ComposeProject string             `json:"compose_project,omitempty" yaml:"compose_project,omitempty"`
+	CurrentVersion string             `json:"current_version" yaml:"current_version"`
+	LatestVersion  string             `json:"latest_version" yaml:"latest_version"`
+	Status         model.UpdateStatus `json:"status" yaml:"status"`
+	Bump           model.BumpLevel    `json:"bump,omitempty" yaml:"bump,omitempty"`
+	Error          string             `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// csvHeader columnas del formato CSV, una fila por contenedor
+var csvHeader = []string{
+	"hostname", "timestamp", "container", "container_id", "image", "registry", "compose_project",
+	"current_version", "latest_version", "status", "bump", "error",
+}
+
+// NewDocument convierte el reporte a su serialización estable, con los
+// contenedores ordenados por nombre
+func NewDocument(report *model.CheckReport) *Document {
+	doc := &Document{
+		SchemaVersion: SchemaVersion,
+		Hostname:      report.Hostname,
+		Timestamp:     report.Timestamp.Format(time.RFC3339),
+		Summary: Summary{
+			Total:     report.Total,
+			Available: len(report.Available),
+			Failed:    len(report.Failed),
+			UpToDate:  len(report.UpToDate),
+		},
+		Containers: []Container{},
+	}
+
+	for _, list := range [][]model.UpdateInfo{report.Available, report.Failed, report.UpToDate} {
+		for _, update := range list {
+			container := Container{
+				Name:           update.Container.Name,
+				ID:             update.Container.ID,
+				Image:          update.Container.ImageName,
+				Registry:       update.Container.Registry(),
+				ComposeProject: update.Container.ComposeProject(),
+				CurrentVersion: update.CurrentVersion,
+				LatestVersion:  update.LatestVersion,
+				Status:         update.Status(),
+			}
+			switch container.Status {
+			case model.StatusAvailable:
+				container.Bump = update.Bump()
+			case model.StatusFailed:
+				container.Error = update.Error.Error()
+			}
+			doc.Containers = append(doc.Containers, container)
+		}
+	}
+	sort.SliceStable(doc.Containers, func(i, j int) bool {
+		return doc.Containers[i].Name < doc.Containers[j].Name
+	})
+
+	return doc
+}
+
+// Write escribe el reporte en el formato indicado
+func Write(w io.Writer, format Format, report *model.CheckReport, options WriteOptions) error {
+	switch format {
+	case FormatText:
+		return WriteText(w, report, false)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(NewDocument(report))
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(NewDocument(report)); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatCSV:
+		return writeCSV(w, NewDocument(report))
+	case FormatJUnit:
+		return writeJUnit(w, report)
+	case FormatSARIF:
+		return writeSARIF(w, report, options.SARIFBaseDir)
+	case FormatHTML:
+		return writeHTML(w, report)
+	case FormatMarkdown:
+		return writeMarkdown(w, report)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// WriteFile escribe el reporte en un fichero de forma atómica (fichero
+// temporal + rename), creando el directorio si no existe
+func WriteFile(path string, format Format, report *model.CheckReport, options WriteOptions) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, format, report, options); err != nil {
+		return fmt.Errorf("failed to render %s report: %w", format, err)
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic escribe un fichero temporal y lo renombra al destino
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace report file: %w", err)
+	}
+	return nil
+}
+
+// writeCSV escribe una fila por contenedor precedida de la cabecera
+func writeCSV(w io.Writer, doc *Document) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, c := range doc.Containers {
+		row := []string{
+			doc.Hostname, doc.Timestamp, c.Name, c.ID, c.Image, c.Registry, c.ComposeProject,
+			c.CurrentVersion, c.LatestVersion, string(c.Status), string(c.Bump), c.Error,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// update regenera los ficheros golden: go test ./internal/report -update
+var update = flag.Bool("update", false, "update golden files")
+
+// testReport reporte con una actualización de parche, una major, un fallo y
+// una imagen con latest al día; web y api vienen de ficheros de Compose
+func testReport() *model.CheckReport {
+	return &model.CheckReport{
+		Hostname:  "host1",
+		Timestamp: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		Total:     4,
+		Available: []model.UpdateInfo{
+			{
+				Container: model.Container{
+					ID: "a1", Name: "web", ImageName: "nginx:1.25.3",
+					Labels: map[string]string{
+						"com.docker.compose.project":              "stack",
+						"com.docker.compose.project.config_files": "/srv/stack/compose.yaml,/srv/stack/compose.override.yaml",
+					},
+				},
+				CurrentVersion: "1.25.3",
+				LatestVersion:  "1.25.4",
+			},
+			{
+				Container: model.Container{
+					ID: "b2", Name: "api", ImageName: "ghcr.io/acme/api:1.4.0",
+					Labels: map[string]string{
+						"com.docker.compose.project.config_files": "/opt/other/compose.yml",
+					},
+				},
+				CurrentVersion: "1.4.0",
+				LatestVersion:  "2.0.0",
+			},
+		},
+		Failed: []model.UpdateInfo{{
+			Container:      model.Container{ID: "c3", Name: "db", ImageName: "postgres:15"},
+			CurrentVersion: "15",
+			Error:          errors.New(`registry unavailable: "503" <retry>`),
+		}},
+		UpToDate: []model.UpdateInfo{{
+			Container:      model.Container{ID: "d4", Name: "cache", ImageName: "redis"},
+			CurrentVersion: "latest",
+			LatestVersion:  "latest",
+			IsUpToDate:     true,
+		}},
+	}
+}
+
+// assertGolden compara la salida con testdata/<name> o la reescribe con -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// render escribe el reporte de prueba en el formato indicado
+func render(t *testing.T, format Format, options WriteOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, format, testReport(), options); err != nil {
+		t.Fatalf("Write(%s): %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocumentGolden(t *testing.T) {
+	tests := []struct {
+		format Format
+		golden string
+	}{
+		{format: FormatJSON, golden: "report.json"},
+		{format: FormatYAML, golden: "report.yaml"},
+		{format: FormatCSV, golden: "report.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			assertGolden(t, tt.golden, render(t, tt.format, WriteOptions{}))
+		})
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	want := NewDocument(testReport())
+
+	var fromJSON Document
+	if err := json.Unmarshal(render(t, FormatJSON, WriteOptions{}), &fromJSON); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&fromJSON, want) {
+		t.Errorf("JSON round trip:\n%+v\nwant\n%+v", fromJSON, *want)
+	}
+
+	var fromYAML Document
+	if err := yaml.Unmarshal(render(t, FormatYAML, WriteOptions{}), &fromYAML); err != nil {
+		t.Fatalf("invalid YAML: %v", err)
+	}
+	if !reflect.DeepEqual(&fromYAML, want) {
+		t.Errorf("YAML round trip:\n%+v\nwant\n%+v", fromYAML, *want)
+	}
+}
+
+func TestCSVEscaping(t *testing.T) {
+	report := testReport()
+	report.Hostname = "host, eu-west"
+	report.Failed[0].Error = errors.New("registry unavailable: \"503\", retry\nlater")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, report, WriteOptions{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	assertGolden(t, "report-escaping.csv", buf.Bytes())
+
+	// El fichero se vuelve a leer con los mismos valores
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("got %d rows, want the header and 4 containers", len(rows))
+	}
+	db := rows[3]
+	if db[2] != "db" {
+		t.Fatalf("row 3 is %q, want db (rows are sorted by name)", db[2])
+	}
+	if db[0] != report.Hostname || db[len(db)-1] != report.Failed[0].Error.Error() {
+		t.Errorf("escaped values do not round trip: %q", db)
+	}
+}
+
+func TestDocumentEmptyReport(t *testing.T) {
+	report := &model.CheckReport{Hostname: "host1", Timestamp: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, report, WriteOptions{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"containers": []`)) {
+		t.Errorf("an empty report must serialize containers as []:\n%s", buf.Bytes())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatCSV, report, WriteOptions{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want := strings.Join(csvHeader, ",") + "\n"; buf.String() != want {
+		t.Errorf("CSV of an empty report = %q, want only the header %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,293 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "docker-image-checker"
+	toolURI      = "https://github.com/pablopin/docker-image-checker"
+
+	// composeConfigFilesLabel ficheros de Compose desde los que se creó el contenedor
+	composeConfigFilesLabel = "com.docker.compose.project.config_files"
+
+	// sarifSourceRoot identificador de la raíz del repositorio contra la que se
+	// resuelven las URIs relativas de los ficheros de Compose
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+// Identificadores de las reglas SARIF
+const (
+	RuleOutdated       = "outdated"
+	RuleMajorBehind    = "major-behind"
+	RuleUnpinnedLatest = "unpinned-latest"
+	RuleCheckFailed    = "check-failed"
+)
+
+// sarifRules reglas que puede producir el reporte, en orden de índice
+var sarifRules = []sarifRule{
+	{
+		ID:                   RuleOutdated,
+		Name:                 "OutdatedImage",
+		ShortDescription:     sarifText{"Container image is outdated"},
+		FullDescription:      sarifText{"A newer version of the container image is available in its registry (patch, minor or unknown version bump)."},
+		Help:                 sarifText{"Pull the new image and recreate the container."},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	{
+		ID:                   RuleMajorBehind,
+		Name:                 "MajorVersionBehind",
+		ShortDescription:     sarifText{"Container image is a major version behind"},
+		FullDescription:      sarifText{"The registry has a new major version of the container image, which may include breaking changes."},
+		Help:                 sarifText{"Review the release notes of the new major version before upgrading."},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	},
+	{
+		ID:                   RuleUnpinnedLatest,
+		Name:                 "UnpinnedLatestTag",
+		ShortDescription:     sarifText{"Container image uses the latest tag"},
+		FullDescription:      sarifText{"The container runs an image referenced by the floating latest tag (or no tag), so the running version is not reproducible."},
+		Help:                 sarifText{"Pin the image to a version tag or a digest."},
+		DefaultConfiguration: sarifConfiguration{Level: "note"},
+	},
+	{
+		ID:                   RuleCheckFailed,
+		Name:                 "CheckFailed",
+		ShortDescription:     sarifText{"Container image could not be checked"},
+		FullDescription:      sarifText{"The registry check for the container image failed, so its update status is unknown."},
+		Help:                 sarifText{"Check registry credentials and connectivity for the image."},
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+}
+
+// sarifLog documento SARIF 2.1.0 (solo los campos que se usan)
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifText          `json:"shortDescription"`
+	FullDescription      sarifText          `json:"fullDescription"`
+	Help                 sarifText          `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifText         `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          sarifProperties   `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifProperties datos del contenedor adjuntos a cada resultado
+type sarifProperties struct {
+	Hostname       string `json:"hostname"`
+	Container      string `json:"container"`
+	Image          string `json:"image"`
+	CurrentVersion string `json:"currentVersion"`
+	LatestVersion  string `json:"latestVersion,omitempty"`
+}
+
+// writeSARIF escribe un resultado por imagen y regla incumplida. Los ficheros
+// de Compose bajo baseDir se emiten como ubicación física relativa a
+// %SRCROOT%; sin baseDir los resultados solo llevan ubicación lógica.
+func writeSARIF(w io.Writer, report *model.CheckReport, baseDir string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          sarifRules,
+		}},
+		Results: []sarifResult{},
+	}
+	if baseDir != "" {
+		root := (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Clean(baseDir))}).String()
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifSourceRoot: {URI: strings.TrimSuffix(root, "/") + "/"},
+		}
+	}
+
+	for _, list := range [][]model.UpdateInfo{report.Available, report.Failed, report.UpToDate} {
+		for _, update := range list {
+			for _, ruleID := range sarifRuleIDs(update) {
+				run.Results = append(run.Results, newSARIFResult(report.Hostname, update, ruleID, baseDir))
+			}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleIDs reglas que incumple un contenedor
+func sarifRuleIDs(update model.UpdateInfo) []string {
+	var rules []string
+	switch update.Status() {
+	case model.StatusAvailable:
+		if update.Bump() == model.BumpMajor {
+			rules = append(rules, RuleMajorBehind)
+		} else {
+			rules = append(rules, RuleOutdated)
+		}
+	case model.StatusFailed:
+		rules = append(rules, RuleCheckFailed)
+	}
+	if isUnpinnedLatest(update.Container.ImageName) {
+		rules = append(rules, RuleUnpinnedLatest)
+	}
+	return rules
+}
+
+// newSARIFResult crea el resultado de una regla para un contenedor. La
+// ubicación física es el fichero de Compose del contenedor, si se conoce y
+// está dentro de baseDir.
+func newSARIFResult(hostname string, update model.UpdateInfo, ruleID, baseDir string) sarifResult {
+	index := 0
+	for i, rule := range sarifRules {
+		if rule.ID == ruleID {
+			index = i
+		}
+	}
+
+	container := update.Container
+	var message string
+	switch ruleID {
+	case RuleOutdated, RuleMajorBehind:
+		message = fmt.Sprintf("%s (%s) can be updated from %s to %s", container.Name, container.ImageName, update.CurrentVersion, update.LatestVersion)
+	case RuleCheckFailed:
+		message = fmt.Sprintf("%s (%s) could not be checked: %v", container.Name, container.ImageName, update.Error)
+	case RuleUnpinnedLatest:
+		message = fmt.Sprintf("%s runs %s, which is not pinned to a version", container.Name, container.ImageName)
+	}
+
+	location := sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{{
+			Name:               container.Name,
+			FullyQualifiedName: hostname + "/" + container.Name,
+			Kind:               "resource",
+		}},
+	}
+	if files := container.Labels[composeConfigFilesLabel]; files != "" {
+		file, _, _ := strings.Cut(files, ",")
+		if uri, ok := sarifRelativeURI(file, baseDir); ok {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri, URIBaseID: sarifSourceRoot},
+			}
+		}
+	}
+
+	fingerprint := sha256.Sum256([]byte(hostname + "/" + container.Name + "/" + ruleID))
+	result := sarifResult{
+		RuleID:              ruleID,
+		RuleIndex:           index,
+		Level:               sarifRules[index].DefaultConfiguration.Level,
+		Message:             sarifText{message},
+		Locations:           []sarifLocation{location},
+		PartialFingerprints: map[string]string{"containerRule/v1": hex.EncodeToString(fingerprint[:])},
+		Properties: sarifProperties{
+			Hostname:       hostname,
+			Container:      container.Name,
+			Image:          container.ImageName,
+			CurrentVersion: update.CurrentVersion,
+		},
+	}
+	if update.Status() == model.StatusAvailable {
+		result.Properties.LatestVersion = update.LatestVersion
+	}
+	return result
+}
+
+// sarifRelativeURI devuelve la URI del fichero relativa a baseDir; falla si
+// no hay baseDir o el fichero está fuera, porque una ruta absoluta del host
+// no se puede resolver en el repositorio
+func sarifRelativeURI(file, baseDir string) (string, bool) {
+	if baseDir == "" || !filepath.IsAbs(file) {
+		return "", false
+	}
+	rel, err := filepath.Rel(baseDir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return (&url.URL{Path: filepath.ToSlash(rel)}).String(), true
+}
+
+// isUnpinnedLatest indica si la imagen usa el tag latest o ningún tag y no
+// está fijada por digest
+func isUnpinnedLatest(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	lastSlash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if colon <= lastSlash {
+		return true
+	}
+	return image[colon+1:] == "latest"
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSARIFGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseDir string
+		golden  string
+	}{
+		{"without base dir", "", "report.sarif.json"},
+		{"with base dir", "/srv", "report-base.sarif.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(t, FormatSARIF, WriteOptions{SARIFBaseDir: tt.baseDir})
+			assertGolden(t, tt.golden, got)
+
+			var log sarifLog
+			if err := json.Unmarshal(got, &log); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			for _, result := range log.Runs[0].Results {
+				physical := result.Locations[0].PhysicalLocation
+				if physical == nil {
+					continue
+				}
+				// Nunca se emiten rutas absolutas del host
+				uri := physical.ArtifactLocation.URI
+				if strings.HasPrefix(uri, "/") || strings.Contains(uri, "://") || physical.ArtifactLocation.URIBaseID != sarifSourceRoot {
+					t.Errorf("%s: artifact location %+v is not relative to %s", result.Properties.Container, physical.ArtifactLocation, sarifSourceRoot)
+				}
+			}
+		})
+	}
+}
+
+func TestSARIFRelativeURI(t *testing.T) {
+	tests := []struct {
+		file, baseDir string
+		want          string
+		ok            bool
+	}{
+		{"/srv/stack/compose.yaml", "/srv", "stack/compose.yaml", true},
+		{"/srv/stack/compose.yaml", "/srv/", "stack/compose.yaml", true},
+		{"/srv/my stack/compose.yaml", "/srv", "my%20stack/compose.yaml", true},
+		{"/opt/other/compose.yml", "/srv", "", false},
+		{"/srv-old/compose.yml", "/srv", "", false},
+		{"/srv/stack/compose.yaml", "", "", false},
+		{"compose.yaml", "/srv", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := sarifRelativeURI(tt.file, tt.baseDir)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("sarifRelativeURI(%q, %q) = %q, %v; want %q, %v", tt.file, tt.baseDir, got, ok, tt.want, tt.ok)
+		}
+	}
+}
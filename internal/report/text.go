@@ -0,0 +1,67 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+const (
+	colorGreen  = "\033[92m"
+	colorYellow = "\033[93m"
+	colorRed    = "\033[91m"
+	colorReset  = "\033[0m"
+)
+
+// WriteText escribe el reporte legible de la consola, con colores si se indica
+func WriteText(w io.Writer, report *model.CheckReport, color bool) error {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	printf("\n%s\n", i18n.T("report.summary"))
+	printf("   - 🖥️  %s: %s\n", i18n.T("report.host"), report.Hostname)
+	printf("   - 🛟  %s: %s\n", i18n.T("report.available"), paint(colorYellow, fmt.Sprint(len(report.Available))))
+	printf("   - ✅  %s: %d\n", i18n.T("report.checked"), report.Total)
+	printf("   - ❌  %s: %s\n", i18n.T("report.failed"), paint(colorRed, fmt.Sprint(len(report.Failed))))
+
+	if len(report.Available) > 0 {
+		printf("\n%s\n", i18n.T("report.updates"))
+		for _, update := range report.Available {
+			printf("   - 🔄 %s (%s)\n", paint(colorYellow, update.Container.Name), update.Container.ImageName)
+			printf("     • %s: %s\n", i18n.T("report.current"), update.CurrentVersion)
+			printf("     • %s: %s\n", i18n.T("report.latest"), update.LatestVersion)
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		printf("\n%s\n", i18n.T("report.failures"))
+		for _, failed := range report.Failed {
+			printf("   - %s (%s) ❌\n", paint(colorRed, failed.Container.Name), failed.Container.ImageName)
+			if failed.Error != nil {
+				printf("     Error: %v\n", failed.Error)
+			}
+		}
+	}
+
+	if len(report.UpToDate) > 0 {
+		printf("\n%s\n", i18n.T("report.up_to_date", len(report.UpToDate)))
+		for _, upToDate := range report.UpToDate {
+			printf("   - %s (%s)\n", paint(colorGreen, upToDate.Container.Name), upToDate.Container.ImageName)
+		}
+	}
+
+	return err
+}
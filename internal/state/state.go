@@ -0,0 +1,285 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// currentVersion versión del formato del fichero de estado
+const currentVersion = 1
+
+// RemindEveryRun como intervalo de recordatorio devuelve todos los
+// pendientes ya notificados como recordatorios en cada ejecución
+const RemindEveryRun time.Duration = -1
+
+// Entry registra un contenedor pendiente que ya fue notificado
+type Entry struct {
+	Container      string             `json:"container"`
+	Image          string             `json:"image"`
+	Labels         map[string]string  `json:"labels,omitempty"`
+	Status         model.UpdateStatus `json:"status"`
+	CurrentVersion string             `json:"current_version,omitempty"`
+	LatestVersion  string             `json:"latest_version,omitempty"`
+	Error          string             `json:"error,omitempty"`
+	FirstSeen      time.Time          `json:"first_seen"`
+	LastNotified   time.Time          `json:"last_notified"`
+}
+
+// State contiene lo último que se notificó, indexado por nombre de contenedor
+type State struct {
+	Version int               `json:"version"`
+	Pending map[string]*Entry `json:"pending"`
+	// Snoozed contenedores silenciados y hasta cuándo
+	Snoozed map[string]time.Time `json:"snoozed,omitempty"`
+}
+
+// New crea un estado vacío
+func New() *State {
+	return &State{
+		Version: currentVersion,
+		Pending: make(map[string]*Entry),
+		Snoozed: make(map[string]time.Time),
+	}
+}
+
+// Store persiste el estado en un fichero JSON
+type Store struct {
+	path string
+}
+
+// NewStore crea un almacén de estado en la ruta indicada
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load carga el estado desde disco; si el fichero no existe devuelve un estado vacío
+func (s *Store) Load() (*State, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	st := New()
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if st.Pending == nil {
+		st.Pending = make(map[string]*Entry)
+	}
+	if st.Snoozed == nil {
+		st.Snoozed = make(map[string]time.Time)
+	}
+
+	return st, nil
+}
+
+// Save guarda el estado de forma atómica (fichero temporal + rename)
+func (s *Store) Save(st *State) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
+
+// Diff compara el reporte con lo último notificado. Un elemento se considera
+// nuevo si no estaba pendiente con el mismo estado (o, para actualizaciones,
+// si la versión disponible ha cambiado). Los pendientes que ya se notificaron
+// hace más de reminder se devuelven como recordatorios; reminder 0 los
+// desactiva y RemindEveryRun los incluye siempre. Los pendientes que ya no lo
+// están se devuelven como resueltos, clasificados según el motivo.
+func (st *State) Diff(report *model.CheckReport, now time.Time, reminder time.Duration) *model.ChangeSet {
+	changes := &model.ChangeSet{}
+	current := make(map[string]model.UpdateStatus)
+	checked := make(map[string]model.UpdateInfo)
+	for _, list := range [][]model.UpdateInfo{report.UpToDate, report.Available, report.Failed} {
+		for _, info := range list {
+			checked[info.Container.Name] = info
+		}
+	}
+
+	for _, update := range report.Available {
+		current[update.Container.Name] = model.StatusAvailable
+		prev := st.Pending[update.Container.Name]
+		switch {
+		case prev == nil || prev.Status != model.StatusAvailable || prev.LatestVersion != update.LatestVersion:
+			changes.NewUpdates = append(changes.NewUpdates, update)
+		case reminderDue(prev, now, reminder):
+			changes.Reminders = append(changes.Reminders, update)
+		}
+	}
+
+	for _, failed := range report.Failed {
+		current[failed.Container.Name] = model.StatusFailed
+		prev := st.Pending[failed.Container.Name]
+		switch {
+		case prev == nil || prev.Status != model.StatusFailed:
+			changes.NewFailures = append(changes.NewFailures, failed)
+		case reminderDue(prev, now, reminder):
+			changes.Reminders = append(changes.Reminders, failed)
+		}
+	}
+
+	for name, entry := range st.Pending {
+		// Un contenedor silenciado no está resuelto aunque no aparezca en el reporte
+		if st.IsSnoozed(name, now) {
+			continue
+		}
+		if current[name] == entry.Status {
+			continue
+		}
+
+		info, found := checked[name]
+		switch {
+		case !found:
+			info = entry.updateInfo()
+			changes.Removed = append(changes.Removed, info)
+		case current[name] == model.StatusFailed:
+			// Una actualización que ahora falla es un fallo nuevo, no un resuelto
+			continue
+		case entry.Status == model.StatusFailed:
+			changes.Recovered = append(changes.Recovered, info)
+		default:
+			changes.Applied = append(changes.Applied, info)
+		}
+		changes.Resolved = append(changes.Resolved, info)
+	}
+	for _, list := range [][]model.UpdateInfo{changes.Resolved, changes.Recovered, changes.Applied, changes.Removed} {
+		sortByName(list)
+	}
+
+	return changes
+}
+
+// sortByName ordena una lista por nombre de contenedor
+func sortByName(list []model.UpdateInfo) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Container.Name < list[j].Container.Name
+	})
+}
+
+// Apply registra como notificados el reporte y los cambios enviados
+func (st *State) Apply(report *model.CheckReport, changes *model.ChangeSet, now time.Time) {
+	notified := make(map[string]bool)
+	for _, list := range [][]model.UpdateInfo{changes.NewUpdates, changes.NewFailures, changes.Reminders} {
+		for _, info := range list {
+			notified[info.Container.Name] = true
+		}
+	}
+
+	pending := make(map[string]*Entry)
+	add := func(info model.UpdateInfo, status model.UpdateStatus) {
+		entry := &Entry{
+			Container:      info.Container.Name,
+			Image:          info.Container.ImageName,
+			Labels:         info.Container.Labels,
+			Status:         status,
+			CurrentVersion: info.CurrentVersion,
+			LatestVersion:  info.LatestVersion,
+			FirstSeen:      now,
+			LastNotified:   now,
+		}
+		if info.Error != nil {
+			entry.Error = info.Error.Error()
+		}
+		if prev := st.Pending[info.Container.Name]; prev != nil && prev.Status == status {
+			entry.FirstSeen = prev.FirstSeen
+			if !notified[info.Container.Name] {
+				entry.LastNotified = prev.LastNotified
+			}
+		}
+		pending[info.Container.Name] = entry
+	}
+
+	for _, update := range report.Available {
+		add(update, model.StatusAvailable)
+	}
+	for _, failed := range report.Failed {
+		add(failed, model.StatusFailed)
+	}
+
+	st.Pending = pending
+}
+
+// Snooze silencia un contenedor hasta el instante indicado
+func (st *State) Snooze(container string, until time.Time) {
+	st.Snoozed[container] = until
+}
+
+// IsSnoozed indica si un contenedor está silenciado
+func (st *State) IsSnoozed(container string, now time.Time) bool {
+	until, ok := st.Snoozed[container]
+	return ok && now.Before(until)
+}
+
+// WithoutSnoozed devuelve una copia del reporte sin las actualizaciones ni
+// los fallos de los contenedores silenciados, y olvida los silencios caducados
+func (st *State) WithoutSnoozed(report *model.CheckReport, now time.Time) *model.CheckReport {
+	for container, until := range st.Snoozed {
+		if !now.Before(until) {
+			delete(st.Snoozed, container)
+		}
+	}
+	if len(st.Snoozed) == 0 {
+		return report
+	}
+
+	filtered := *report
+	filtered.Available = make([]model.UpdateInfo, 0, len(report.Available))
+	filtered.Failed = make([]model.UpdateInfo, 0, len(report.Failed))
+	for _, update := range report.Available {
+		if !st.IsSnoozed(update.Container.Name, now) {
+			filtered.Available = append(filtered.Available, update)
+		}
+	}
+	for _, failed := range report.Failed {
+		if !st.IsSnoozed(failed.Container.Name, now) {
+			filtered.Failed = append(filtered.Failed, failed)
+		}
+	}
+	return &filtered
+}
+
+// reminderDue indica si toca recordar un elemento pendiente
+func reminderDue(entry *Entry, now time.Time, reminder time.Duration) bool {
+	return reminder == RemindEveryRun || (reminder > 0 && now.Sub(entry.LastNotified) >= reminder)
+}
+
+// updateInfo reconstruye la información de un elemento que ya no aparece
+// en la verificación. No lleva el error anterior: un contenedor que ya no se
+// verifica no está fallando, y con él Status() lo daría por fallido.
+func (e *Entry) updateInfo() model.UpdateInfo {
+	return model.UpdateInfo{
+		Container: model.Container{
+			Name:      e.Container,
+			ImageName: e.Image,
+			Labels:    e.Labels,
+		},
+		CurrentVersion: e.CurrentVersion,
+		LatestVersion:  e.LatestVersion,
+		IsUpToDate:     true,
+	}
+}
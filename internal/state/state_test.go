@@ -0,0 +1,287 @@
+package state
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+var now = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+const week = 7 * 24 * time.Hour
+
+func update(name, latest string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container:      model.Container{Name: name, ImageName: name + ":1.0.0"},
+		CurrentVersion: "1.0.0",
+		LatestVersion:  latest,
+	}
+}
+
+func failure(name string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container: model.Container{Name: name, ImageName: name + ":1.0.0"},
+		Error:     errors.New("registry unavailable"),
+	}
+}
+
+func upToDate(name string) model.UpdateInfo {
+	return model.UpdateInfo{
+		Container:      model.Container{Name: name, ImageName: name + ":1.0.0"},
+		CurrentVersion: "1.0.0",
+		LatestVersion:  "1.0.0",
+		IsUpToDate:     true,
+	}
+}
+
+// pending crea un estado con los elementos notificados hace age
+func pending(age time.Duration, entries ...*Entry) *State {
+	st := New()
+	for _, entry := range entries {
+		entry.FirstSeen = now.Add(-age)
+		entry.LastNotified = now.Add(-age)
+		st.Pending[entry.Container] = entry
+	}
+	return st
+}
+
+func pendingUpdate(name, latest string) *Entry {
+	return &Entry{Container: name, Image: name + ":1.0.0", Status: model.StatusAvailable, CurrentVersion: "1.0.0", LatestVersion: latest}
+}
+
+func pendingFailure(name string) *Entry {
+	return &Entry{Container: name, Image: name + ":1.0.0", Status: model.StatusFailed, Error: "registry unavailable"}
+}
+
+// names devuelve los nombres de contenedor de una lista
+func names(list []model.UpdateInfo) []string {
+	var result []string
+	for _, info := range list {
+		result = append(result, info.Container.Name)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    *State
+		report   *model.CheckReport
+		reminder time.Duration
+		want     map[string][]string
+	}{
+		{
+			name:   "new update and failure",
+			state:  New(),
+			report: &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}, Failed: []model.UpdateInfo{failure("db")}},
+			want:   map[string][]string{"new_updates": {"web"}, "new_failures": {"db"}},
+		},
+		{
+			name:     "already notified before reminder",
+			state:    pending(6*24*time.Hour, pendingUpdate("web", "1.1.0"), pendingFailure("db")),
+			report:   &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}, Failed: []model.UpdateInfo{failure("db")}},
+			reminder: week,
+			want:     map[string][]string{},
+		},
+		{
+			name:     "reminder after reminder_days",
+			state:    pending(week, pendingUpdate("web", "1.1.0"), pendingFailure("db")),
+			report:   &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}, Failed: []model.UpdateInfo{failure("db")}},
+			reminder: week,
+			want:     map[string][]string{"reminders": {"web", "db"}},
+		},
+		{
+			name:   "reminders disabled",
+			state:  pending(365*24*time.Hour, pendingUpdate("web", "1.1.0")),
+			report: &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}},
+			want:   map[string][]string{},
+		},
+		{
+			name:     "remind every run",
+			state:    pending(time.Minute, pendingUpdate("web", "1.1.0")),
+			report:   &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}},
+			reminder: RemindEveryRun,
+			want:     map[string][]string{"reminders": {"web"}},
+		},
+		{
+			name:     "newer version of a pending update",
+			state:    pending(time.Hour, pendingUpdate("web", "1.1.0")),
+			report:   &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.2.0")}},
+			reminder: week,
+			want:     map[string][]string{"new_updates": {"web"}},
+		},
+		{
+			name:     "pending update now failing",
+			state:    pending(time.Hour, pendingUpdate("web", "1.1.0")),
+			report:   &model.CheckReport{Failed: []model.UpdateInfo{failure("web")}},
+			reminder: week,
+			want:     map[string][]string{"new_failures": {"web"}},
+		},
+		{
+			name:     "resolved items",
+			state:    pending(time.Hour, pendingUpdate("web", "1.1.0"), pendingFailure("db"), pendingUpdate("old", "2.0.0")),
+			report:   &model.CheckReport{UpToDate: []model.UpdateInfo{upToDate("web"), upToDate("db")}},
+			reminder: week,
+			want:     map[string][]string{"resolved": {"db", "old", "web"}, "recovered": {"db"}, "applied": {"web"}, "removed": {"old"}},
+		},
+		{
+			name: "snoozed container is not resolved",
+			state: func() *State {
+				st := pending(time.Hour, pendingUpdate("web", "1.1.0"))
+				st.Snooze("web", now.Add(time.Hour))
+				return st
+			}(),
+			report:   &model.CheckReport{},
+			reminder: week,
+			want:     map[string][]string{},
+		},
+		{
+			name: "expired snooze resolves",
+			state: func() *State {
+				st := pending(time.Hour, pendingUpdate("web", "1.1.0"))
+				st.Snooze("web", now.Add(-time.Minute))
+				return st
+			}(),
+			report:   &model.CheckReport{},
+			reminder: week,
+			want:     map[string][]string{"resolved": {"web"}, "removed": {"web"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := tt.state.Diff(tt.report, now, tt.reminder)
+			got := map[string][]string{}
+			for key, list := range map[string][]model.UpdateInfo{
+				"new_updates":  changes.NewUpdates,
+				"new_failures": changes.NewFailures,
+				"reminders":    changes.Reminders,
+				"resolved":     changes.Resolved,
+				"recovered":    changes.Recovered,
+				"applied":      changes.Applied,
+				"removed":      changes.Removed,
+			} {
+				if len(list) > 0 {
+					got[key] = names(list)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+			if changes.IsEmpty() != (len(tt.want) == 0) {
+				t.Errorf("IsEmpty() = %v with changes %v", changes.IsEmpty(), got)
+			}
+		})
+	}
+}
+
+func TestDiffResolvedTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   *Entry
+		report  *model.CheckReport
+		reason  string
+		resolve bool
+	}{
+		{"failing checks again", pendingFailure("web"), &model.CheckReport{UpToDate: []model.UpdateInfo{upToDate("web")}}, "recovered", true},
+		{"failing now has update", pendingFailure("web"), &model.CheckReport{Available: []model.UpdateInfo{update("web", "1.1.0")}}, "recovered", true},
+		{"update applied", pendingUpdate("web", "1.1.0"), &model.CheckReport{UpToDate: []model.UpdateInfo{upToDate("web")}}, "applied", true},
+		{"update removed", pendingUpdate("web", "1.1.0"), &model.CheckReport{}, "removed", true},
+		{"failure removed", pendingFailure("web"), &model.CheckReport{}, "removed", true},
+		{"update now failing", pendingUpdate("web", "1.1.0"), &model.CheckReport{Failed: []model.UpdateInfo{failure("web")}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := pending(time.Hour, tt.entry).Diff(tt.report, now, week)
+
+			if got := len(changes.Resolved) == 1; got != tt.resolve {
+				t.Fatalf("resolved = %v, want %v", names(changes.Resolved), tt.resolve)
+			}
+			reasons := map[string][]model.UpdateInfo{
+				"recovered": changes.Recovered,
+				"applied":   changes.Applied,
+				"removed":   changes.Removed,
+			}
+			for reason, list := range reasons {
+				want := 0
+				if reason == tt.reason {
+					want = 1
+				}
+				if len(list) != want {
+					t.Errorf("%s = %v, want %d item(s)", reason, names(list), want)
+				}
+			}
+
+			// Un contenedor que ya no se verifica no cuenta como fallido
+			for _, removed := range changes.Removed {
+				if removed.Status() != model.StatusUpToDate || removed.Error != nil {
+					t.Errorf("removed %s has status %s and error %v", removed.Container.Name, removed.Status(), removed.Error)
+				}
+				if removed.Container.ImageName != "web:1.0.0" {
+					t.Errorf("removed %s lost its image %q", removed.Container.Name, removed.Container.ImageName)
+				}
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	firstSeen := now.Add(-week)
+	st := pending(week, pendingUpdate("web", "1.1.0"), pendingUpdate("api", "2.0.0"), pendingFailure("db"))
+	report := &model.CheckReport{
+		Available: []model.UpdateInfo{update("web", "1.1.0"), update("api", "2.0.0"), update("cache", "7.2.0")},
+		UpToDate:  []model.UpdateInfo{upToDate("db")},
+	}
+	// Solo se envió el recordatorio de web y la actualización nueva de cache
+	changes := &model.ChangeSet{
+		NewUpdates: []model.UpdateInfo{update("cache", "7.2.0")},
+		Reminders:  []model.UpdateInfo{update("web", "1.1.0")},
+	}
+
+	st.Apply(report, changes, now)
+
+	if len(st.Pending) != 3 || st.Pending["db"] != nil {
+		t.Fatalf("pending = %v, want web, api and cache", st.Pending)
+	}
+	for name, want := range map[string]struct{ firstSeen, lastNotified time.Time }{
+		"web":   {firstSeen, now},
+		"api":   {firstSeen, firstSeen},
+		"cache": {now, now},
+	} {
+		entry := st.Pending[name]
+		if !entry.FirstSeen.Equal(want.firstSeen) || !entry.LastNotified.Equal(want.lastNotified) {
+			t.Errorf("%s: first seen %v, last notified %v; want %v, %v", name, entry.FirstSeen, entry.LastNotified, want.firstSeen, want.lastNotified)
+		}
+	}
+
+	// Lo enviado ya no vuelve a salir; api sigue pendiente de recordatorio
+	changes = st.Diff(report, now.Add(time.Hour), week)
+	if got := names(changes.Reminders); !reflect.DeepEqual(got, []string{"api"}) || len(changes.NewUpdates)+len(changes.Resolved) > 0 {
+		t.Errorf("Diff after Apply = %+v, want only the api reminder", changes)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir() + "/state/notified.json")
+	st, err := store.Load()
+	if err != nil || len(st.Pending) != 0 {
+		t.Fatalf("Load() of missing file = %v, %v; want empty state", st, err)
+	}
+
+	st.Pending["web"] = pendingUpdate("web", "1.1.0")
+	st.Snooze("db", now.Add(time.Hour))
+	if err := store.Save(st); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Pending["web"].LatestVersion != "1.1.0" || !loaded.IsSnoozed("db", now) {
+		t.Errorf("loaded state %+v does not match saved state", loaded)
+	}
+}
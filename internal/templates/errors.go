@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError error de sintaxis de una plantilla con su posición
+type ParseError struct {
+	Name string
+	Line int
+	// Column es 0 cuando text/template no permite localizar el error en la línea
+	Column int
+	Msg    string
+}
+
+// Error implementa la interfaz error con el formato fichero:línea:columna
+func (e *ParseError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Name, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.Name, e.Line, e.Msg)
+}
+
+// parseErrorPattern formato de los errores de parseo de text/template
+var parseErrorPattern = regexp.MustCompile(`^template: (.+?):(\d+): (.*)$`)
+
+// tokenPatterns extraen del mensaje el elemento que ha provocado el error
+// (ej: function "foo" not defined, bad character U+0026 '&')
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`"((?:[^"\\]|\\.)+)"`),
+	regexp.MustCompile(`'(.)'`),
+}
+
+// newParseError convierte un error de text/template en un ParseError,
+// buscando la columna del elemento citado en el mensaje dentro de la línea
+func newParseError(content string, err error) error {
+	match := parseErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	line, convErr := strconv.Atoi(match[2])
+	if convErr != nil {
+		return err
+	}
+
+	parseErr := &ParseError{Name: match[1], Line: line, Msg: match[3]}
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return parseErr
+	}
+
+	for _, pattern := range tokenPatterns {
+		token := pattern.FindStringSubmatch(parseErr.Msg)
+		if token == nil {
+			continue
+		}
+		if unquoted, unquoteErr := strconv.Unquote(`"` + token[1] + `"`); unquoteErr == nil {
+			token[1] = unquoted
+		}
+		if i := strings.Index(lines[line-1], token[1]); i >= 0 {
+			parseErr.Column = utf8.RuneCountInString(lines[line-1][:i]) + 1
+			break
+		}
+	}
+	return parseErr
+}
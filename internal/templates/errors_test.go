@@ -0,0 +1,134 @@
+package templates
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		msg     string
+	}{
+		{
+			name:    "undefined function",
+			content: "{{ .Hostname }}\n  {{ foo .Hostname }}\n",
+			line:    2,
+			column:  6,
+			msg:     `function "foo" not defined`,
+		},
+		{
+			name:    "quoted token",
+			content: "first\nsecond\n{{ .Hostname & }}",
+			line:    3,
+			column:  14,
+			msg:     `unexpected "&" in operand`,
+		},
+		{
+			name:    "unrecognized character",
+			content: "{{ .Hostname ¿ }}",
+			line:    1,
+			column:  14,
+			msg:     "unrecognized character in action: U+00BF '¿'",
+		},
+		{
+			name:    "column counts runes",
+			content: "🔄 ñ {{ nope }}",
+			line:    1,
+			column:  8,
+			msg:     `function "nope" not defined`,
+		},
+		{
+			// Sin elemento citado solo se conoce la línea
+			name:    "unclosed action",
+			content: "ok\n{{ .Hostname ",
+			line:    2,
+			msg:     "unclosed action",
+		},
+		{
+			name:    "unexpected end",
+			content: "{{ if .Hostname }}\nsin cerrar\n",
+			line:    3,
+			msg:     "unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemplate(t, t.TempDir(), "custom.tmpl", tt.content)
+			_, err := Load(Options{Name: "test", Path: path})
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Load error = %v, want a *ParseError", err)
+			}
+			if parseErr.Name != "custom.tmpl" || parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("position = %s:%d:%d, want custom.tmpl:%d:%d",
+					parseErr.Name, parseErr.Line, parseErr.Column, tt.line, tt.column)
+			}
+			if !strings.Contains(parseErr.Msg, tt.msg) {
+				t.Errorf("message = %q, want %q", parseErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseErrorFormat(t *testing.T) {
+	withColumn := &ParseError{Name: "custom.tmpl", Line: 2, Column: 6, Msg: `function "foo" not defined`}
+	if got, want := withColumn.Error(), `custom.tmpl:2:6: function "foo" not defined`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	withoutColumn := &ParseError{Name: "custom.tmpl", Line: 2, Msg: "unclosed action"}
+	if got, want := withoutColumn.Error(), "custom.tmpl:2: unclosed action"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Los errores con otro formato se devuelven sin cambios
+	other := errors.New("something else")
+	if got := newParseError("", other); got != other {
+		t.Errorf("got %v, want the original error", got)
+	}
+}
+
+func TestExecErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "missing field",
+			content: "{{ .Hostname }}\n  {{ .Nope }}",
+			want:    "custom.tmpl:2:5: executing",
+		},
+		{
+			name:    "helper error",
+			content: "line\n{{ dateIn \"\" \"Mars/Olympus\" .Report.Timestamp }}",
+			want:    `custom.tmpl:2:3: executing "custom.tmpl" at <dateIn "" "Mars/Olympus" .Report.Timestamp>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			set, err := Load(Options{Name: "test", Path: writeTemplate(t, dir, "custom.tmpl", tt.content)})
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			_, err = set.Render(SampleData(model.EventUpdates, "host"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Render error = %v, want %q", err, tt.want)
+			}
+			if err != nil && strings.Contains(err.Error(), filepath.Dir(dir)) {
+				t.Errorf("Render error %q includes the absolute path", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// escapeFuncName nombre de la función que se añade a cada acción de la plantilla
+const escapeFuncName = "escape"
+
+// rawFuncName permite desactivar el escapado en una acción concreta
+const rawFuncName = "raw"
+
+// htmlEscaper escapa los caracteres reservados de HTML
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// EscapeHTML escapa un texto para HTML (y el parse_mode HTML de Telegram)
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// markdownEscaper escapa los caracteres con significado en Markdown
+var markdownEscaper = newBackslashEscaper(`\_*[]()` + "`" + `#|<>`)
+
+// EscapeMarkdown escapa un texto para Markdown (Slack, Discord, Gotify, ...)
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownV2Escaper escapa los caracteres reservados de MarkdownV2
+var markdownV2Escaper = newBackslashEscaper(`\_*[]()~` + "`" + `>#+-=|{}.!`)
+
+// EscapeMarkdownV2 escapa un texto para el parse_mode MarkdownV2 de Telegram
+func EscapeMarkdownV2(s string) string {
+	return markdownV2Escaper.Replace(s)
+}
+
+// markdownV2Reserved caracteres que MarkdownV2 exige escapar fuera de las
+// entidades de formato (los de formato, como * o _, se permiten sin escapar)
+const markdownV2Reserved = "()#+-={}.!"
+
+// CheckMarkdownV2 comprueba que el texto literal de una plantilla no contenga
+// caracteres reservados de MarkdownV2 sin escapar, que Telegram rechaza. Se
+// permiten los bloques de código y la URL de los enlaces [texto](url).
+func CheckMarkdownV2(text string) error {
+	inCode, inURL := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\':
+			i++
+		case c == '`':
+			inCode = !inCode
+		case inCode:
+		case inURL:
+			inURL = c != ')'
+		case c == '(' && i > 0 && text[i-1] == ']':
+			inURL = true
+		case strings.IndexByte(markdownV2Reserved, c) >= 0,
+			c == '>' && i > 0 && text[i-1] != '\n':
+			return fmt.Errorf("unescaped %q in MarkdownV2 text %q (use \\%c)", c, excerpt(text, i), c)
+		}
+	}
+	return nil
+}
+
+// excerpt devuelve la línea del texto que contiene la posición i
+func excerpt(text string, i int) string {
+	start := strings.LastIndexByte(text[:i], '\n') + 1
+	end := strings.IndexByte(text[i:], '\n')
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : i+end]
+}
+
+// newBackslashEscaper crea un Replacer que antepone \ a los caracteres indicados
+func newBackslashEscaper(chars string) *strings.Replacer {
+	var pairs []string
+	for _, c := range chars {
+		pairs = append(pairs, string(c), `\`+string(c))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// escapeFuncs funciones necesarias para parsear una plantilla con autoescapado
+func escapeFuncs(escaper func(string) string) template.FuncMap {
+	return template.FuncMap{
+		escapeFuncName: func(value interface{}) string {
+			return escaper(fmt.Sprint(value))
+		},
+		rawFuncName: func(value interface{}) string {
+			return fmt.Sprint(value)
+		},
+	}
+}
+
+// escapingFuncs funciones que ya dejan el valor escapado; las acciones que
+// terminan en ellas no se vuelven a escapar
+var escapingFuncs = map[string]bool{
+	escapeFuncName:     true,
+	rawFuncName:        true,
+	"escapeHTML":       true,
+	"escapeMarkdown":   true,
+	"escapeMarkdownV2": true,
+}
+
+// escapeText aplica el escaper al texto literal de una plantilla. Se usa con
+// las plantillas embebidas, que son texto plano y no contienen formato.
+func escapeText(t *template.Template, escaper func(string) string) {
+	if t.Tree == nil {
+		return
+	}
+	walkList(t.Tree.Root, func(node parse.Node) {
+		if n, ok := node.(*parse.TextNode); ok {
+			n.Text = []byte(escaper(string(n.Text)))
+		}
+	})
+}
+
+// literalText concatena el texto literal de una plantilla en orden, sin las
+// acciones (cuyo valor ya se escapa)
+func literalText(t *template.Template) string {
+	var b strings.Builder
+	if t.Tree == nil {
+		return ""
+	}
+	walkList(t.Tree.Root, func(node parse.Node) {
+		if n, ok := node.(*parse.TextNode); ok {
+			b.Write(n.Text)
+		}
+	})
+	return b.String()
+}
+
+// autoEscape añade la función de escapado al final de cada acción que produce
+// salida, de forma similar a html/template: los valores de los datos quedan
+// escapados y el texto literal de la plantilla se envía tal cual. Las acciones
+// que terminan en raw o en una función de escapado no se modifican.
+func autoEscape(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			walkList(t.Tree.Root, func(node parse.Node) {
+				if n, ok := node.(*parse.ActionNode); ok {
+					escapePipe(n.Pipe)
+				}
+			})
+		}
+	}
+}
+
+// walkList recorre en orden los nodos de una lista de la plantilla,
+// incluidos los de los bloques if, range y with
+func walkList(list *parse.ListNode, visit func(parse.Node)) {
+	if list == nil {
+		return
+	}
+
+	for _, node := range list.Nodes {
+		visit(node)
+		switch n := node.(type) {
+		case *parse.IfNode:
+			walkList(n.List, visit)
+			walkList(n.ElseList, visit)
+		case *parse.RangeNode:
+			walkList(n.List, visit)
+			walkList(n.ElseList, visit)
+		case *parse.WithNode:
+			walkList(n.List, visit)
+			walkList(n.ElseList, visit)
+		}
+	}
+}
+
+// escapePipe añade "| escape" a una acción si produce salida
+func escapePipe(pipe *parse.PipeNode) {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+		return
+	}
+
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	if len(last.Args) > 0 {
+		if ident, ok := last.Args[0].(*parse.IdentifierNode); ok && escapingFuncs[ident.Ident] {
+			return
+		}
+	}
+
+	pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{
+		NodeType: parse.NodeCommand,
+		Pos:      last.Pos,
+		Args:     []parse.Node{parse.NewIdentifier(escapeFuncName).SetPos(last.Pos)},
+	})
+}
@@ -0,0 +1,112 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+func TestDefaultsEscapeLiteralTextForMarkdownV2(t *testing.T) {
+	set, err := Load(Options{Name: "test", Escaper: EscapeMarkdownV2})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	for _, event := range model.EventTypes {
+		out, err := set.Render(SampleData(event, "host-1.example"))
+		if err != nil {
+			t.Fatalf("Render(%s): %v", event, err)
+		}
+		// Las plantillas por defecto no tienen formato: todo reservado va escapado
+		if err := CheckMarkdownV2(out); err != nil {
+			t.Errorf("Render(%s) is not valid MarkdownV2: %v\n%s", event, err, out)
+		}
+	}
+
+	out, err := set.Render(SampleData(model.EventUpdates, "host"))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := `\- 🔄 web \(nginx:1\.25\.3\): 1\.25\.3 → 1\.25\.4`; !strings.Contains(out, want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+}
+
+func TestDefaultsEscapeLiteralTextForHTML(t *testing.T) {
+	set, err := Load(Options{Name: "test", Escaper: EscapeHTML})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	data := SampleData(model.EventUpdates, "<host>")
+	out, err := set.Render(data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{"&lt;host&gt;", "- 🔄 web (nginx:1.25.3)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCustomTemplateCheckText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "unescaped literal",
+			content: "Host: {{ .Hostname }} (done).",
+			wantErr: `unescaped '('`,
+		},
+		{
+			name:    "unescaped dash",
+			content: "- {{ .Hostname }}",
+			wantErr: `unescaped '-'`,
+		},
+		{
+			name:    "markup and escapes",
+			content: "*Host*: _{{ .Hostname }}_ \\(done\\)\\.",
+			want:    `*Host*: _my\-host\.lan_ \(done\)\.`,
+		},
+		{
+			name:    "link and code",
+			content: "[{{ .Hostname }}](https://example.com/a-b.c) `x-y.z`",
+			want:    "[my\\-host\\.lan](https://example.com/a-b.c) `x-y.z`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "custom.tmpl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			set, err := Load(Options{Name: "test", Path: path, Escaper: EscapeMarkdownV2, CheckText: CheckMarkdownV2})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			out, err := set.Render(&model.NotificationData{Hostname: "my-host.lan"})
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("got %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
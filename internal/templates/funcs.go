@@ -0,0 +1,160 @@
+package templates
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pablopin/docker-image-checker/internal/i18n"
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// Group conjunto de actualizaciones que comparten una clave (ver groupBy)
+type Group struct {
+	Key   string
+	Items []model.UpdateInfo
+}
+
+// Funcs devuelve la librería de funciones disponible en todas las plantillas.
+// Los argumentos siguen el orden de text/template para poder encadenarlas
+// (ej: {{ .Container.Name | truncate 20 | upper }}).
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		// Texto
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"title":     title,
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, repl, s string) string { return strings.ReplaceAll(s, old, repl) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"join":      func(sep string, items []string) string { return strings.Join(items, sep) },
+		"split":     func(sep, s string) []string { return strings.Split(s, sep) },
+		"repeat":    func(n int, s string) string { return strings.Repeat(s, max(n, 0)) },
+		"truncate":  truncate,
+		"plural":    plural,
+		"default":   defaultValue,
+
+		// Fechas
+		"now":    time.Now,
+		"date":   date,
+		"dateIn": dateIn,
+
+		// Agrupación y aritmética
+		"groupBy": groupBy,
+		"add":     func(a, b int) int { return a + b },
+		"sub":     func(a, b int) int { return a - b },
+
+		// Traducción con el catálogo del idioma configurado
+		"T":  i18n.T,
+		"TN": i18n.TN,
+
+		// Escapado explícito
+		"escapeHTML":       EscapeHTML,
+		"escapeMarkdown":   EscapeMarkdown,
+		"escapeMarkdownV2": EscapeMarkdownV2,
+	}
+}
+
+// title pone en mayúscula la primera letra de cada palabra
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(prev) || prev == '-' || prev == '_' {
+			prev = r
+			return unicode.ToUpper(r)
+		}
+		prev = r
+		return r
+	}, s)
+}
+
+// truncate recorta un texto a n caracteres, terminando en "…" si se ha cortado
+func truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// plural elige la forma singular o plural según n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
+
+// defaultValue devuelve value o, si es el valor cero de su tipo, def
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	if v := reflect.ValueOf(value); v.IsZero() {
+		return def
+	}
+	return value
+}
+
+// defaultDateLayout formato usado cuando la plantilla no indica uno
+const defaultDateLayout = "2006-01-02 15:04"
+
+// date formatea una fecha en la zona horaria local
+func date(layout string, t time.Time) string {
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+	return t.Local().Format(layout)
+}
+
+// dateIn formatea una fecha en una zona horaria IANA (ej: "Europe/Madrid")
+func dateIn(layout, tz string, t time.Time) (string, error) {
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return "", fmt.Errorf("unknown time zone %q", tz)
+	}
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+	return t.In(location).Format(layout), nil
+}
+
+// groupKeys claves admitidas por groupBy
+var groupKeys = map[string]func(model.UpdateInfo) string{
+	"registry": func(u model.UpdateInfo) string { return u.Container.Registry() },
+	"project":  func(u model.UpdateInfo) string { return u.Container.ComposeProject() },
+	"status":   func(u model.UpdateInfo) string { return string(u.Status()) },
+	"bump":     func(u model.UpdateInfo) string { return string(u.Bump()) },
+}
+
+// groupBy agrupa actualizaciones por registry, project, status o bump,
+// ordenando los grupos por clave
+func groupBy(key string, items []model.UpdateInfo) ([]Group, error) {
+	keyOf, ok := groupKeys[key]
+	if !ok {
+		return nil, fmt.Errorf("groupBy: unknown key %q (expected registry, project, status or bump)", key)
+	}
+
+	index := make(map[string]int)
+	var groups []Group
+	for _, item := range items {
+		k := keyOf(item)
+		i, found := index[k]
+		if !found {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{Key: k})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups, nil
+}
@@ -0,0 +1,147 @@
+package templates
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// execute ejecuta una plantilla con la librería de funciones
+func execute(t *testing.T, text string, data interface{}) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(Funcs()).Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	var out strings.Builder
+	err = tmpl.Execute(&out, data)
+	return out.String(), err
+}
+
+func TestFuncs(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		data interface{}
+		want string
+	}{
+		{name: "upper and lower", text: `{{ upper "web" }} {{ lower "API" }}`, want: "WEB api"},
+		{name: "title", text: `{{ title "web-app my_db cache" }}`, want: "Web-App My_Db Cache"},
+		{name: "trim", text: `[{{ trim "  web \n" }}]`, want: "[web]"},
+		{name: "replace in a pipeline", text: `{{ "nginx:1.25" | replace ":" "@" }}`, want: "nginx@1.25"},
+		{name: "contains", text: `{{ contains "nginx" "docker.io/nginx" }}`, want: "true"},
+		{name: "prefix and suffix", text: `{{ hasPrefix "ghcr" "ghcr.io/a" }} {{ hasSuffix "-alpine" "1.25-alpine" }}`, want: "true true"},
+		{name: "split and join", text: `{{ split "," "a,b,c" | join " | " }}`, want: "a | b | c"},
+		{name: "repeat", text: `{{ repeat 3 "=" }}`, want: "==="},
+		{name: "negative repeat", text: `[{{ repeat -1 "=" }}]`, want: "[]"},
+		{name: "truncate", text: `{{ "registry.example.com" | truncate 8 }}`, want: "registr…"},
+		{name: "truncate counts runes", text: `{{ "🔄🔄🔄" | truncate 2 }}`, want: "🔄…"},
+		{name: "no truncation", text: `{{ "web" | truncate 3 }} {{ "web" | truncate 0 }}`, want: "web web"},
+		{name: "plural", text: `{{ plural 1 "update" "updates" }} {{ plural 0 "update" "updates" }} {{ plural 2 "update" "updates" }}`, want: "update updates updates"},
+		{name: "default for empty", text: `{{ "" | default "n/a" }} {{ 0 | default 5 }}`, want: "n/a 5"},
+		{name: "default keeps value", text: `{{ "web" | default "n/a" }}`, want: "web"},
+		{name: "default for nil", text: `{{ .Missing | default "none" }}`, data: map[string]interface{}{}, want: "none"},
+		{name: "arithmetic", text: `{{ add 2 3 }} {{ sub 2 3 }}`, want: "5 -1"},
+		{name: "date in zone", text: `{{ dateIn "2006-01-02 15:04 MST" "Europe/Madrid" . }}`, data: when, want: "2024-03-01 13:30 CET"},
+		{name: "date in zone with default layout", text: `{{ dateIn "" "UTC" . }}`, data: when, want: "2024-03-01 12:30"},
+		{name: "date", text: `{{ date "" . }}`, data: when, want: when.Local().Format(defaultDateLayout)},
+		{name: "escape", text: `{{ escapeHTML "<b>" }} {{ escapeMarkdownV2 "1.25" }}`, want: `&lt;b&gt; 1\.25`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := execute(t, tt.text, tt.data)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateInUnknownZone(t *testing.T) {
+	_, err := execute(t, `{{ dateIn "" "Mars/Olympus" . }}`, time.Now())
+	if err == nil || !strings.Contains(err.Error(), `unknown time zone "Mars/Olympus"`) {
+		t.Errorf("error = %v, want an unknown time zone error", err)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	update := func(name, image, current, latest string, labels map[string]string) model.UpdateInfo {
+		return model.UpdateInfo{
+			Container:      model.Container{Name: name, ImageName: image, Labels: labels},
+			CurrentVersion: current,
+			LatestVersion:  latest,
+		}
+	}
+	shop := map[string]string{"com.docker.compose.project": "shop"}
+	failed := update("db", "postgres:16", "16", "", shop)
+	failed.Error = errors.New("timeout")
+	items := []model.UpdateInfo{
+		update("web", "nginx:1.25.3", "1.25.3", "1.25.4", shop),
+		update("api", "ghcr.io/acme/api:1.0.0", "1.0.0", "2.0.0", nil),
+		failed,
+		update("cache", "redis:7.0.0", "7.0.0", "7.1.0", nil),
+	}
+
+	tests := []struct {
+		key  string
+		want map[string][]string
+		keys []string
+	}{
+		{
+			key:  "registry",
+			keys: []string{"docker.io", "ghcr.io"},
+			want: map[string][]string{"docker.io": {"web", "db", "cache"}, "ghcr.io": {"api"}},
+		},
+		{
+			key:  "project",
+			keys: []string{"", "shop"},
+			want: map[string][]string{"": {"api", "cache"}, "shop": {"web", "db"}},
+		},
+		{
+			key:  "status",
+			keys: []string{"available", "failed"},
+			want: map[string][]string{"available": {"web", "api", "cache"}, "failed": {"db"}},
+		},
+		{
+			key:  "bump",
+			keys: []string{"major", "minor", "patch", "unknown"},
+			want: map[string][]string{"major": {"api"}, "minor": {"cache"}, "patch": {"web"}, "unknown": {"db"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			groups, err := groupBy(tt.key, items)
+			if err != nil {
+				t.Fatalf("groupBy: %v", err)
+			}
+			var keys []string
+			for _, group := range groups {
+				keys = append(keys, group.Key)
+				var names []string
+				for _, item := range group.Items {
+					names = append(names, item.Container.Name)
+				}
+				if strings.Join(names, ",") != strings.Join(tt.want[group.Key], ",") {
+					t.Errorf("group %q = %v, want %v", group.Key, names, tt.want[group.Key])
+				}
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.keys, ",") {
+				t.Errorf("keys = %v, want %v (sorted)", keys, tt.keys)
+			}
+		})
+	}
+
+	if _, err := groupBy("image", items); err == nil || !strings.Contains(err.Error(), `unknown key "image"`) {
+		t.Errorf("error = %v, want an unknown key error", err)
+	}
+}
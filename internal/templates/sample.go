@@ -0,0 +1,94 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// SampleData devuelve datos de ejemplo que producen el evento indicado, para
+// previsualizar plantillas sin ejecutar una verificación
+func SampleData(event model.EventType, hostname string) *model.NotificationData {
+	nginx := model.UpdateInfo{
+		Container:      model.Container{ID: "3f4e5d6c7b8a", Name: "web", ImageName: "nginx:1.25.3", Status: "running"},
+		CurrentVersion: "1.25.3",
+		LatestVersion:  "1.25.4",
+	}
+	postgres := model.UpdateInfo{
+		Container: model.Container{
+			ID: "9a8b7c6d5e4f", Name: "db", ImageName: "postgres:15.5", Status: "running",
+			Labels: map[string]string{"com.docker.compose.project": "app"},
+		},
+		CurrentVersion: "15.5",
+		LatestVersion:  "16.1",
+	}
+	redis := model.UpdateInfo{
+		Container:      model.Container{ID: "1a2b3c4d5e6f", Name: "cache", ImageName: "redis:7.2.4", Status: "running"},
+		CurrentVersion: "7.2.4",
+		LatestVersion:  "7.2.4",
+		IsUpToDate:     true,
+	}
+	private := model.UpdateInfo{
+		Container:      model.Container{ID: "6f5e4d3c2b1a", Name: "api", ImageName: "registry.example.com/team/api:2.3.0", Status: "running"},
+		CurrentVersion: "2.3.0",
+		Error:          errors.New("registry.example.com: 401 Unauthorized"),
+	}
+
+	report := &model.CheckReport{
+		Hostname:  hostname,
+		Timestamp: time.Now(),
+	}
+	data := &model.NotificationData{Report: report, Hostname: hostname}
+
+	switch event {
+	case model.EventFailures:
+		report.Available = []model.UpdateInfo{nginx, postgres}
+		report.Failed = []model.UpdateInfo{private}
+		report.UpToDate = []model.UpdateInfo{redis}
+	case model.EventRecovery:
+		nginx.IsUpToDate, nginx.CurrentVersion = true, nginx.LatestVersion
+		private.Error, private.IsUpToDate, private.LatestVersion = nil, true, private.CurrentVersion
+		report.UpToDate = []model.UpdateInfo{private, redis, nginx}
+		data.Changes = &model.ChangeSet{
+			Resolved:  []model.UpdateInfo{private, nginx},
+			Recovered: []model.UpdateInfo{private},
+			Applied:   []model.UpdateInfo{nginx},
+		}
+	default:
+		report.Available = []model.UpdateInfo{nginx, postgres}
+		report.UpToDate = []model.UpdateInfo{redis}
+	}
+	report.Total = len(report.Available) + len(report.Failed) + len(report.UpToDate)
+
+	return data
+}
+
+// LoadFixture lee los datos de una notificación desde un fichero JSON, que
+// puede contener un CheckReport o un NotificationData completo
+func LoadFixture(path string) (*model.NotificationData, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var data model.NotificationData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	if data.Report == nil {
+		var report model.CheckReport
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture: %w", err)
+		}
+		data.Report = &report
+	}
+	if data.Hostname == "" {
+		data.Hostname = data.Report.Hostname
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,178 @@
+// Package templates carga y ejecuta las plantillas de los mensajes de
+// notificación, con una librería de funciones común y plantillas por defecto
+// embebidas para cada tipo de evento.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+//go:embed defaults/*.tmpl
+var defaults embed.FS
+
+// commonTemplate bloques compartidos ("summary", "available", "failed",
+// "resolved", "no-report") que cualquier plantilla puede invocar
+const commonTemplate = "defaults/common.tmpl"
+
+// Options indica qué plantillas cargar para un notificador
+type Options struct {
+	// Name identifica la plantilla en los mensajes de error
+	Name string
+	// Path plantilla usada para todos los eventos sin plantilla propia
+	Path string
+	// Events plantilla por tipo de evento; tiene prioridad sobre Path
+	Events map[model.EventType]string
+	// Escaper, si se indica, se aplica a cada valor que produce la plantilla.
+	// El texto literal de las plantillas embebidas también se escapa; el de
+	// las plantillas propias se envía tal cual, con el formato que contenga.
+	Escaper func(string) string
+	// CheckText, si se indica, valida el texto literal de las plantillas
+	// propias al cargarlas (ej: CheckMarkdownV2)
+	CheckText func(string) error
+}
+
+// Set plantillas de un notificador, una por tipo de evento
+type Set struct {
+	templates map[model.EventType]*template.Template
+}
+
+// Load carga las plantillas de cada evento. Para cada uno se usa, por orden,
+// Events[evento], Path o la plantilla embebida por defecto. Aquí se validan
+// los nombres de evento de la configuración y de las URLs de notificación.
+func Load(options Options) (*Set, error) {
+	for event := range options.Events {
+		if !isEvent(event) {
+			return nil, fmt.Errorf("unknown template event %q (expected updates, failures or recovery)", event)
+		}
+	}
+
+	set := &Set{templates: make(map[model.EventType]*template.Template)}
+	for _, event := range model.EventTypes {
+		name, content, builtin, err := source(options, event)
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := parseTemplate(name, content, builtin, options)
+		if err != nil {
+			return nil, err
+		}
+		set.templates[event] = tmpl
+	}
+
+	return set, nil
+}
+
+// Render ejecuta la plantilla correspondiente al evento de la notificación
+func (s *Set) Render(data *model.NotificationData) (string, error) {
+	var buf bytes.Buffer
+	if err := s.templates[data.Event()].Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// source devuelve el nombre y el contenido de la plantilla de un evento, e
+// indica si es la plantilla embebida por defecto
+func source(options Options, event model.EventType) (string, string, bool, error) {
+	path := options.Events[event]
+	if path == "" {
+		path = options.Path
+	}
+
+	if path == "" {
+		content, err := defaults.ReadFile("defaults/" + string(event) + ".tmpl")
+		if err != nil {
+			return "", "", false, fmt.Errorf("failed to read default template: %w", err)
+		}
+		return options.Name + "-" + string(event), string(content), true, nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read template file: %w", err)
+	}
+	return filepath.Base(path), string(content), false, nil
+}
+
+// parseTemplate crea una plantilla con la librería de funciones y los bloques
+// comunes. Sin escaper, escape y raw siguen disponibles pero no modifican nada.
+// builtin indica que content es una plantilla embebida, cuyo texto literal
+// se escapa igual que los bloques comunes.
+func parseTemplate(name, content string, builtin bool, options Options) (*template.Template, error) {
+	common, err := defaults.ReadFile(commonTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default template: %w", err)
+	}
+
+	escaper := options.Escaper
+	funcs := Funcs()
+	escape := escaper
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	for key, fn := range escapeFuncs(escape) {
+		funcs[key] = fn
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Parse(string(common))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default template: %w", err)
+	}
+	if escaper != nil {
+		for _, t := range tmpl.Templates() {
+			escapeText(t, escaper)
+		}
+	}
+
+	// Las plantillas que define content son las que no existían tras
+	// parsear los bloques comunes (o las que content redefine)
+	inherited := make(map[*parse.Tree]bool)
+	for _, t := range tmpl.Templates() {
+		inherited[t.Tree] = true
+	}
+	if _, err := tmpl.Parse(content); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", newParseError(content, err))
+	}
+	for _, t := range tmpl.Templates() {
+		if inherited[t.Tree] {
+			continue
+		}
+		if builtin && escaper != nil {
+			escapeText(t, escaper)
+		}
+		if !builtin && options.CheckText != nil {
+			if err := options.CheckText(literalText(t)); err != nil {
+				return nil, fmt.Errorf("invalid template %q: %w", t.Name(), err)
+			}
+		}
+	}
+
+	if escaper != nil {
+		autoEscape(tmpl)
+	}
+	return tmpl, nil
+}
+
+// isEvent indica si un tipo de evento es conocido
+func isEvent(event model.EventType) bool {
+	for _, known := range model.EventTypes {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pablopin/docker-image-checker/internal/model"
+)
+
+// writeTemplate crea una plantilla en dir y devuelve su ruta
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadEventSelection(t *testing.T) {
+	dir := t.TempDir()
+	shared := writeTemplate(t, dir, "shared.tmpl", "shared {{ .Hostname }}")
+	updates := writeTemplate(t, dir, "updates.tmpl", "updates {{ len .Report.Available }}")
+
+	tests := []struct {
+		name    string
+		options Options
+		want    map[model.EventType]string
+	}{
+		{
+			name:    "event template wins over path",
+			options: Options{Path: shared, Events: map[model.EventType]string{model.EventUpdates: updates}},
+			want: map[model.EventType]string{
+				model.EventUpdates:  "updates 2",
+				model.EventFailures: "shared host",
+				model.EventRecovery: "shared host",
+			},
+		},
+		{
+			name:    "path for every event",
+			options: Options{Path: shared},
+			want: map[model.EventType]string{
+				model.EventUpdates:  "shared host",
+				model.EventFailures: "shared host",
+				model.EventRecovery: "shared host",
+			},
+		},
+		{
+			// Sin Path, los eventos sin plantilla propia usan la embebida
+			name:    "defaults for the rest",
+			options: Options{Events: map[model.EventType]string{model.EventUpdates: updates}},
+			want: map[model.EventType]string{
+				model.EventUpdates: "updates 2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Name = "test"
+			set, err := Load(tt.options)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			for _, event := range model.EventTypes {
+				got, err := set.Render(SampleData(event, "host"))
+				if err != nil {
+					t.Fatalf("Render(%s): %v", event, err)
+				}
+				want, custom := tt.want[event]
+				switch {
+				case custom && got != want:
+					t.Errorf("Render(%s) = %q, want %q", event, got, want)
+				case !custom && (strings.HasPrefix(got, "shared") || strings.HasPrefix(got, "updates")):
+					t.Errorf("Render(%s) = %q, want the built-in template", event, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	broken := writeTemplate(t, dir, "broken.tmpl", "{{ .Hostname ")
+
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name:    "unknown event",
+			options: Options{Events: map[model.EventType]string{"update": broken}},
+			want:    `unknown template event "update" (expected updates, failures or recovery)`,
+		},
+		{
+			name:    "missing file",
+			options: Options{Events: map[model.EventType]string{model.EventRecovery: filepath.Join(dir, "missing.tmpl")}},
+			want:    "failed to read template file",
+		},
+		{
+			name:    "parse error",
+			options: Options{Path: broken},
+			want:    "broken.tmpl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Name = "test"
+			if _, err := Load(tt.options); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
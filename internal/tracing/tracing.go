@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName nombre del tracer de la aplicación
+	instrumentationName = "github.com/pablopin/docker-image-checker"
+	defaultServiceName  = "docker-image-checker"
+	// tracesPath ruta OTLP/HTTP de las trazas si el endpoint no indica otra
+	tracesPath = "/v1/traces"
+)
+
+// Options configuración de la exportación de trazas
+type Options struct {
+	// Endpoint URL del colector OTLP/HTTP (ej: http://otel-collector:4318);
+	// vacío usa OTEL_EXPORTER_OTLP_ENDPOINT o localhost:4318
+	Endpoint string
+	// Headers cabeceras adicionales, por ejemplo de autenticación
+	Headers map[string]string
+	// ServiceName nombre del servicio (por defecto docker-image-checker)
+	ServiceName string
+	// SampleRatio fracción de ejecuciones trazadas (por defecto 1)
+	SampleRatio float64
+}
+
+// Shutdown exporta las trazas pendientes y libera el exportador
+type Shutdown func(ctx context.Context) error
+
+// Setup exporta las trazas por OTLP/HTTP y registra el proveedor global
+func Setup(ctx context.Context, options Options) (Shutdown, error) {
+	var exporterOptions []otlptracehttp.Option
+	if options.Endpoint != "" {
+		endpoint, err := url.Parse(options.Endpoint)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid tracing endpoint %q", options.Endpoint)
+		}
+		if endpoint.Path == "" || endpoint.Path == "/" {
+			endpoint.Path = tracesPath
+		}
+		exporterOptions = append(exporterOptions, otlptracehttp.WithEndpointURL(endpoint.String()))
+	}
+	if len(options.Headers) > 0 {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithHeaders(options.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	return setup(sdktrace.NewBatchSpanProcessor(exporter), options)
+}
+
+// SetupWithExporter registra el proveedor global con un exportador
+// cualquiera, por ejemplo tracetest.NewInMemoryExporter en pruebas. Los
+// spans se exportan de forma síncrona al terminar.
+func SetupWithExporter(exporter sdktrace.SpanExporter, options Options) (Shutdown, error) {
+	return setup(sdktrace.NewSimpleSpanProcessor(exporter), options)
+}
+
+// setup crea el proveedor con el procesador indicado y lo registra como global
+func setup(processor sdktrace.SpanProcessor, options Options) (Shutdown, error) {
+	serviceName := options.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	hostname, _ := os.Hostname()
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.HostName(hostname),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	ratio := options.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start inicia un span con el tracer de la aplicación. Sin Setup el
+// proveedor global no registra nada.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marca el span como fallido si hay error y lo cierra
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport envuelve un http.RoundTripper con un span de cliente por
+// petición. No propaga la traza en las cabeceras porque los destinos son
+// registros de terceros.
+type Transport struct {
+	// Base transporte real (por defecto http.DefaultTransport)
+	Base http.RoundTripper
+}
+
+// RoundTrip implementa http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := otel.Tracer(instrumentationName).Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.Redacted()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+	}
+	span.End()
+	return resp, nil
+}